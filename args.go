@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"strings"
+)
+
+const (
+	// setArg option followed by a 'key=value' argument, '--set=key=value' is also accepted.
+	setArg = "--set"
+	// defineArg prefix of a '-Dkey=value' argument.
+	defineArg = "-D"
+	// argsEnd end of options, following arguments are ignored.
+	argsEnd = "--"
+)
+
+// LoadArgs load '--set key=value', '--set=key=value' and '-Dkey=value' arguments,
+// other arguments (i.e. '-Dry-run') are ignored, as well as all arguments after '--'.
+// Keys may be dotted, values are kept as is, spaces aside ('!unset' remove a key).
+// Arguments override values already loaded, whatever the merge policy, and env vars
+// even with EnvOverFiles precedence, so they should be loaded after any other source.
+// A malformed argument return a ParseError holding its index within args.
+func (b *ConfigBuilder) LoadArgs(args []string) (GoConfig, error) {
+	loaded := newSourceValues(SourceCommandLine, "")
+	for i := 0; i < len(args) && argsEnd != args[i]; i++ {
+		index := i
+		var line string
+		switch arg := args[i]; {
+		case setArg == arg:
+			i++
+			if i >= len(args) {
+				return b.conf, &ParseError{line: index, arg: true, msg: "missing value after '" + setArg + "'"}
+			}
+			line = args[i]
+			index = i
+		case strings.HasPrefix(arg, setArg+"="):
+			line = arg[len(setArg)+1:]
+		case strings.HasPrefix(arg, defineArg) && strings.Contains(arg, "="):
+			line = arg[len(defineArg):]
+		default:
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return b.conf, &ParseError{line: index, arg: true, msg: "missing '=' : '" + line + "'"}
+		}
+		key, value := strings.TrimSpace(line[:eq]), strings.TrimSpace(line[eq+1:])
+		if "" == normalizeKey(key) {
+			return b.conf, &ParseError{line: index, arg: true, msg: "missing key : '" + line + "'"}
+		}
+		if unsetValue == value {
+			loaded.setFrom(key, unset, Source{Kind: SourceCommandLine}, LastWins)
+		} else {
+			loaded.setFrom(key, value, Source{Kind: SourceCommandLine}, LastWins)
+		}
+	}
+	// Merge new config and current one.
+	b.WithMergePolicy(LastWins).merge(loaded)
+
+	return b.conf, nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,103 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check LoadArgs.
+func TestArgs0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.LoadJSON(strings.NewReader("{ \"database\": { \"port\": 5432, \"user\": \"john\" }, \"name\": \"json\" }"))
+
+	config, err := builder.LoadArgs([]string{"serve", "--set", "database.port=5433",
+		"--set=database.host = localhost", "-Dname=args", "-Ddatabase.user=!unset", "-v"})
+	if nil != err {
+		t.Error("LoadArgs Failed", err)
+	}
+	expected := map[string]string{
+		"database.port": "5433",
+		"database.host": "localhost",
+		"name":          "args",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	if _, serr := config.GetString("database.user"); nil == serr {
+		t.Error("Key 'database.user' should be removed")
+	}
+	source, _ := config.Origin("database.port")
+	if SourceCommandLine != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+}
+
+// Check LoadArgs ignore unrelated arguments, and keep values as is.
+func TestArgs2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetInlineComments(true)
+	config, err := builder.LoadArgs([]string{"-Dry-run", "--set", "color=#fff", "--", "-Dname=ignored", "--set"})
+	if nil != err {
+		t.Error("LoadArgs Failed", err)
+	}
+	str, serr := config.GetString("color")
+	if nil != serr || "#fff" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	if _, serr = config.GetString("name"); nil == serr {
+		t.Error("Arguments after '--' should be ignored")
+	}
+}
+
+// Check arguments override env vars, even when env vars override files.
+func TestArgs3(t *testing.T) {
+	builder := NewBuilder("Args3_", nil)
+	builder.SetEnvPrecedence(EnvOverFiles)
+	builder.LoadJSON(strings.NewReader("{ \"database\": { \"url\": \"json url\" } }"))
+	os.Setenv("ARGS3_DATABASE_URL", "env url")
+	defer os.Unsetenv("ARGS3_DATABASE_URL")
+
+	config, _ := builder.LoadArgs([]string{"--set", "database.url=args url"})
+	if str, err := config.GetString("database.url"); nil != err || "args url" != str {
+		t.Error("Wrong value found :", str, err)
+	}
+	if source, _ := config.Origin("database.url"); SourceCommandLine != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+}
+
+// Check LoadArgs errors.
+func TestArgs1(t *testing.T) {
+	tests := []struct {
+		args  []string
+		index int
+	}{
+		{[]string{"-Dname=ok", "--set", "missing"}, 2},
+		{[]string{"-Dname=ok", "--set"}, 1},
+		{[]string{"-D=value"}, 0},
+		{[]string{"--set==value"}, 0},
+	}
+	for _, test := range tests {
+		builder := NewBuilder("", nil)
+		_, err := builder.LoadArgs(test.args)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Error("ParseError expected for", test.args, err)
+			continue
+		}
+		if test.index != perr.line || !perr.arg {
+			t.Error("Wrong index for", test.args, ":", perr)
+		}
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
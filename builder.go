@@ -8,17 +8,27 @@ package goconfig
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
 )
 
 // ConfigBuilder Used to create Config Objects and parse config files.
 type ConfigBuilder struct {
 	conf               *ConfigImpl
 	ignoreMissingFiles bool
+	inlineComments     bool
+	mergePolicy        MergePolicy
+	arrayRule          arrayRule
+	arrayRules         map[string]arrayRule
 }
 
 // NewBuilder Instantiate a new builder
@@ -27,9 +37,9 @@ type ConfigBuilder struct {
 func NewBuilder(prefix string, defaults map[string]interface{}) *ConfigBuilder {
 	prefix = strings.ToUpper(prefix)
 	obj := make(map[string]interface{})
-	def := &ConfigDefault{prefix: prefix, values: defaults, maxRecursion: 5}
+	def := &ConfigDefault{prefix: prefix, values: defaults, maxRecursion: 5, listQuotes: defaultListQuotes}
 	conf := &ConfigImpl{values: obj, parent: nil, def: def}
-	result := &ConfigBuilder{conf: conf, ignoreMissingFiles: false}
+	result := &ConfigBuilder{conf: conf, ignoreMissingFiles: false, mergePolicy: FirstWins}
 
 	return result
 }
@@ -44,6 +54,89 @@ func (b *ConfigBuilder) IgnoreMissingFiles() bool {
 	return b.ignoreMissingFiles
 }
 
+// SetInlineComments should txt parser strip trailing ' # comment' from values or not.
+// When enabled, values may be quoted with " or ' to keep a literal '#'.
+func (b *ConfigBuilder) SetInlineComments(value bool) {
+	b.inlineComments = value
+}
+
+// InlineComments check if txt parser strip trailing comments or not
+func (b *ConfigBuilder) InlineComments() bool {
+	return b.inlineComments
+}
+
+// SetMergePolicy define how loaded values are merged with existing ones.
+// FirstWins (default) keep existing values, LastWins override them.
+func (b *ConfigBuilder) SetMergePolicy(policy MergePolicy) {
+	b.mergePolicy = policy
+}
+
+// MergePolicy return current merge policy
+func (b *ConfigBuilder) MergePolicy() MergePolicy {
+	return b.mergePolicy
+}
+
+// WithMergePolicy return a builder sharing the same config, using another merge policy.
+// Used to change policy for a single call :
+//  builder.WithMergePolicy(LastWins).LoadFiles("local.json")
+func (b *ConfigBuilder) WithMergePolicy(policy MergePolicy) *ConfigBuilder {
+	result := *b
+	result.mergePolicy = policy
+	return &result
+}
+
+// SetArrayStrategy define how slices found in several sources are merged.
+// field is the name of the field identifying items for ArrayMergeByKey.
+func (b *ConfigBuilder) SetArrayStrategy(strategy ArrayStrategy, field ...string) {
+	b.arrayRule = newArrayRule(strategy, field)
+}
+
+// ArrayStrategy return current default array strategy
+func (b *ConfigBuilder) ArrayStrategy() ArrayStrategy {
+	return b.arrayRule.strategy
+}
+
+// SetKeyArrayStrategy define how slices found in several sources for key are merged.
+// key is a full dotted key (i.e. "http.allowed_hosts"), it overrides default strategy.
+// field is the name of the field identifying items for ArrayMergeByKey.
+func (b *ConfigBuilder) SetKeyArrayStrategy(key string, strategy ArrayStrategy, field ...string) {
+	if nil == b.arrayRules {
+		b.arrayRules = make(map[string]arrayRule)
+	}
+	b.arrayRules[key] = newArrayRule(strategy, field)
+}
+
+// newArrayRule build an arrayRule, field defaults to "name".
+func newArrayRule(strategy ArrayStrategy, field []string) arrayRule {
+	rule := arrayRule{strategy: strategy, field: "name"}
+	if len(field) > 0 {
+		rule.field = field[0]
+	}
+	return rule
+}
+
+// merge values loaded from a source into current config.
+func (b *ConfigBuilder) merge(loaded *sourceValues) {
+	b.applyProfiles(loaded)
+	if nil == b.conf.def.origins {
+		b.conf.def.origins = make(map[string]Source)
+	}
+	m := &merger{policy: b.mergePolicy, array: b.arrayRule, arrays: b.arrayRules,
+		source: loaded.source, from: loaded.origins, origins: b.conf.def.origins}
+	m.mergeMap(loaded.values, b.conf.values, "")
+}
+
+// SetEnvPrecedence define precedence of env vars : EnvLast (default),
+// EnvOverDefaults or EnvOverFiles. Applies to getters and ${} expansion.
+func (b *ConfigBuilder) SetEnvPrecedence(precedence EnvPrecedence) {
+	b.conf.def.envPrecedence = precedence
+}
+
+// EnvPrecedence return current env vars precedence
+func (b *ConfigBuilder) EnvPrecedence() EnvPrecedence {
+	return b.conf.def.envPrecedence
+}
+
 // Config return current config
 func (b *ConfigBuilder) Config() GoConfig {
 	return b.conf
@@ -74,6 +167,11 @@ func (b *ConfigBuilder) MaxRecursion() uint {
 // LoadJSON Load a map from a Json Stream
 // merge loaded value with previous one.
 func (b *ConfigBuilder) LoadJSON(r io.Reader) (GoConfig, error) {
+	return b.loadJSON(r, "")
+}
+
+// loadJSON Load a map from a Json Stream read from filename (may be empty).
+func (b *ConfigBuilder) loadJSON(r io.Reader, filename string) (GoConfig, error) {
 
 	jsonBytes, err := ioutil.ReadAll(r)
 	if err != nil {
@@ -83,37 +181,122 @@ func (b *ConfigBuilder) LoadJSON(r io.Reader) (GoConfig, error) {
 	if err := json.Unmarshal(jsonBytes, &obj); err != nil {
 		return nil, err
 	}
-	mergeMap(obj, b.conf.values)
+	markUnset(obj)
+	b.merge(&sourceValues{source: Source{Kind: SourceJSON, File: filename}, values: obj})
 	return b.conf, nil
 }
 
+// LoadYAML Load a map from a YAML Stream
+// merge loaded value with previous one.
+func (b *ConfigBuilder) LoadYAML(r io.Reader) (GoConfig, error) {
+	return b.loadYAML(r, "")
+}
+
+// loadYAML Load a map from a YAML Stream read from filename (may be empty).
+func (b *ConfigBuilder) loadYAML(r io.Reader, filename string) (GoConfig, error) {
+
+	yamlBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(yamlBytes, &obj); err != nil {
+		return nil, err
+	}
+	for k, v := range obj {
+		obj[k] = normalizeYAML(v)
+	}
+	markUnset(obj)
+	b.merge(&sourceValues{source: Source{Kind: SourceYAML, File: filename}, values: obj})
+	return b.conf, nil
+}
+
+// normalizeYAML convert nested map[interface{}]interface{} produced by yaml
+// into map[string]interface{}, so that YAML trees look like JSON ones.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	}
+	return value
+}
+
+// LoadTOML Load a map from a TOML Stream
+// merge loaded value with previous one.
+// Tables are stored as nested maps, arrays of tables as slices of maps
+// and datetimes as time.Time.
+func (b *ConfigBuilder) LoadTOML(r io.Reader) (GoConfig, error) {
+	return b.loadTOML(r, "")
+}
+
+// loadTOML Load a map from a TOML Stream read from filename (may be empty).
+func (b *ConfigBuilder) loadTOML(r io.Reader, filename string) (GoConfig, error) {
+
+	tomlBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if _, err := toml.Decode(string(tomlBytes), &obj); err != nil {
+		return nil, err
+	}
+	for k, v := range obj {
+		obj[k] = normalizeTOML(v)
+	}
+	b.merge(&sourceValues{source: Source{Kind: SourceTOML, File: filename}, values: obj})
+	return b.conf, nil
+}
+
+// normalizeTOML convert arrays of tables ([]map[string]interface{})
+// into []interface{}, so that TOML trees look like JSON ones.
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeTOML(item)
+		}
+		return v
+	case []map[string]interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeTOML(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+		return v
+	}
+	return value
+}
+
 // LoadTxt Load a map from a text Stream
 // merge loaded value with previous one.
+// Relative '@include' are resolved from working directory.
 func (b *ConfigBuilder) LoadTxt(r io.Reader) (GoConfig, error) {
+	return b.loadTxt(r, nil, "")
+}
 
-	scanner := bufio.NewScanner(r)
-	lineNb := 0
-	obj := make(map[string]interface{})
-	conf := &ConfigImpl{values: obj, parent: nil, def: b.conf.def}
-	for scanner.Scan() {
-		lineNb++
-		line := strings.TrimSpace(scanner.Text())
-		// ignore empty lines and comments
-		if "" != line && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
-			// Parse key=value
-			words := strings.SplitN(line, "=", 2)
-			if len(words) != 2 {
-				return b.conf, &ParseError{line: lineNb, msg: "missing '=' : '" + line + "'"}
-			}
-			key := strings.TrimSpace(words[0])
-			value := strings.TrimSpace(words[1])
-			// Set Value in a spare config
-			conf.SetValue(key, value)
-		}
+// loadTxt Load a map from a text Stream read from filename (may be empty).
+// includes are read from fsys, or from os when fsys is nil.
+func (b *ConfigBuilder) loadTxt(r io.Reader, fsys fs.FS, filename string) (GoConfig, error) {
 
+	loaded := newSourceValues(SourceTxt, filename)
+	if err := b.parseTxt(r, fsys, filename, nil, loaded); nil != err {
+		return b.conf, err
 	}
 	// Merge new config and current one.
-	mergeMap(conf.values, b.conf.values)
+	b.merge(loaded)
 
 	return b.conf, nil
 }
@@ -126,10 +309,33 @@ func (b *ConfigBuilder) LoadJSONFile(filename string) (GoConfig, error) {
 	}
 	defer f.Close()
 	r := bufio.NewReader(f)
-	return b.LoadJSON(r)
+	return b.loadJSON(r, filename)
+}
+
+// LoadYAMLFile load from a file
+func (b *ConfigBuilder) LoadYAMLFile(filename string) (GoConfig, error) {
+	f, err := os.Open(filename)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	return b.loadYAML(r, filename)
+}
+
+// LoadTOMLFile load from a file
+func (b *ConfigBuilder) LoadTOMLFile(filename string) (GoConfig, error) {
+	f, err := os.Open(filename)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	return b.loadTOML(r, filename)
 }
 
 // LoadTxtFile load from a file
+// Relative '@include' are resolved from file directory.
 func (b *ConfigBuilder) LoadTxtFile(filename string) (GoConfig, error) {
 	f, err := os.Open(filename)
 	if nil != err {
@@ -137,28 +343,71 @@ func (b *ConfigBuilder) LoadTxtFile(filename string) (GoConfig, error) {
 	}
 	defer f.Close()
 	r := bufio.NewReader(f)
-	return b.LoadTxt(r)
+	return b.loadTxt(r, nil, filename)
+}
+
+// parserFor choose a parser according to file extension.
+// When extension is .json parse it as a json file,
+// .yaml or .yml as a yaml file, .toml as a toml file,
+// .ini as an ini file, .properties as a java properties file,
+// .env as a dotenv file, otherwise as a txt file
+func (b *ConfigBuilder) parserFor(fsys fs.FS, filename string) func(io.Reader) (GoConfig, error) {
+	var parser func(io.Reader, string) (GoConfig, error)
+	switch path.Ext(filename) {
+	case ".json":
+		parser = b.loadJSON
+	case ".yaml", ".yml":
+		parser = b.loadYAML
+	case ".toml":
+		parser = b.loadTOML
+	case ".ini":
+		parser = b.loadINI
+	case ".properties":
+		parser = b.loadProperties
+	case ".env":
+		parser = b.loadDotEnv
+	default:
+		return func(r io.Reader) (GoConfig, error) {
+			return b.loadTxt(r, fsys, filename)
+		}
+	}
+	return func(r io.Reader) (GoConfig, error) {
+		return parser(r, filename)
+	}
+}
+
+// openFile open a file from fsys, or from os when fsys is nil.
+func openFile(fsys fs.FS, filename string) (fs.File, error) {
+	if nil == fsys {
+		return os.Open(filename)
+	}
+	return fsys.Open(filename)
 }
 
 // LoadFiles load from files. Guess file type by reading extension.
 // When extension is .json parse it as a json file,
-// otherwise as a txt file
+// .yaml or .yml as a yaml file, .toml as a toml file,
+// .ini as an ini file, .properties as a java properties file,
+// .env as a dotenv file, otherwise as a txt file
 func (b *ConfigBuilder) LoadFiles(filenames ...string) (GoConfig, error) {
-	// wich method to use to parse the file, default to Txt
-	parser := b.LoadTxt
+	return b.loadFiles(nil, filenames)
+}
+
+// loadFiles load files from fsys, or from os when fsys is nil.
+// Overlays of active profiles are loaded with each file.
+func (b *ConfigBuilder) loadFiles(fsys fs.FS, filenames []string) (GoConfig, error) {
 	for _, filename := range filenames {
-		f, err := os.Open(filename)
-		if nil != err {
-			if !(b.ignoreMissingFiles && os.IsNotExist(err)) {
-				return nil, err
+		for _, name := range b.profileFiles(filename) {
+			f, err := openFile(fsys, name)
+			if nil != err {
+				// profile overlays are optional
+				if !((b.ignoreMissingFiles || name != filename) && os.IsNotExist(err)) {
+					return nil, err
+				}
+				continue
 			}
-		} else {
 			// Choose a parser
-			if ".json" == path.Ext(filename) {
-				parser = b.LoadJSON
-			} else {
-				parser = b.LoadTxt
-			}
+			parser := b.parserFor(fsys, name)
 
 			// parse file
 			r := bufio.NewReader(f)
@@ -174,4 +423,48 @@ func (b *ConfigBuilder) LoadFiles(filenames ...string) (GoConfig, error) {
 	return b.conf, nil
 }
 
+// LoadDir load all files of a directory, in lexical order (00-base.json, 10-site.conf, ...).
+// Sub directories and hidden files (starting with '.') are ignored,
+// once profiles are set, profile overlays (app.dev.json next to app.json) are loaded only for active profiles.
+// File type is guessed from extension as in LoadFiles.
+func (b *ConfigBuilder) LoadDir(dirname string) (GoConfig, error) {
+	infos, err := ioutil.ReadDir(dirname)
+	if nil != err {
+		if b.ignoreMissingFiles && os.IsNotExist(err) {
+			return b.conf, nil
+		}
+		return nil, err
+	}
+	// ReadDir returns entries sorted by name
+	var filenames []string
+	for _, info := range infos {
+		if !info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
+			filenames = append(filenames, filepath.Join(dirname, info.Name()))
+		}
+	}
+	return b.LoadFiles(b.dropProfileOverlays(filenames)...)
+}
+
+// LoadGlob load all files matching a pattern (i.e. /etc/myapp/conf.d/*.json), in lexical order.
+// Directories and hidden files (starting with '.') are ignored,
+// once profiles are set, profile overlays are loaded only for active profiles.
+// File type is guessed from extension as in LoadFiles.
+func (b *ConfigBuilder) LoadGlob(pattern string) (GoConfig, error) {
+	matches, err := filepath.Glob(pattern)
+	if nil != err {
+		return nil, err
+	}
+	// Glob returns matches sorted by name
+	var filenames []string
+	for _, filename := range matches {
+		if strings.HasPrefix(filepath.Base(filename), ".") {
+			continue
+		}
+		if info, err := os.Stat(filename); nil == err && !info.IsDir() {
+			filenames = append(filenames, filename)
+		}
+	}
+	return b.LoadFiles(b.dropProfileOverlays(filenames)...)
+}
+
 // vi:set fileencoding=utf-8 tabstop=4 ai
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // test de valeurs globales avec deux sections
@@ -345,4 +346,265 @@ func TestBuilder11(t *testing.T) {
 	}
 }
 
+// Check LoadYAML
+func TestBuilder12(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "nope: true\nkey: value\nsub:\n  bool: false\n  1: one\n"
+	config, err := builder.LoadYAML(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadYAML Failed", err)
+	}
+
+	val, serr := config.GetBool("nope")
+	if nil != serr {
+		t.Error("Key 'nope' not found", serr)
+	}
+	if !val {
+		t.Error("Wrong value found :", val)
+	}
+
+	// nested maps should use string keys
+	str, serr = config.GetString("sub.1")
+	if nil != serr {
+		t.Error("Key 'sub.1' not found", serr)
+	}
+	if "one" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	sub, serr := config.GetConfig("sub")
+	if nil != serr {
+		t.Error("Key 'sub' not found", serr)
+	}
+	val, serr = sub.GetBool("bool", true)
+	if nil != serr || val {
+		t.Error("Wrong value found :", val, serr)
+	}
+}
+
+// Check LoadYAML with invalid yaml.
+func TestBuilder12b(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "key: [value\n"
+	_, err := builder.LoadYAML(strings.NewReader(str))
+
+	if nil == err {
+		t.Error("LoadYAML should Failed")
+	}
+}
+
+// Check LoadYAMLFile
+func TestBuilder13(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	config, err := builder.LoadYAMLFile("testdata/config00.yaml")
+
+	if nil != err {
+		t.Error("Load file  Failed", err)
+	}
+	str, serr := config.GetString("database.user")
+	if nil != serr {
+		t.Error("Key 'database.user' not found", serr)
+	}
+	if "john" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	_, err = builder.LoadYAMLFile("testdata/config01.yaml")
+	if nil == err {
+		t.Error("Load file  should Fail")
+	}
+
+	_, err = builder.LoadYAMLFile("missing.yaml")
+	if nil == err {
+		t.Error("Load missing file should Fail")
+	}
+}
+
+// Check multiple file parsing with yaml file.
+func TestBuilder14(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	config, err := builder.LoadFiles("testdata/config00.yaml", "testdata/config00.txt")
+
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr := config.GetString("database.pwd")
+	if nil != serr {
+		t.Error("Key 'database.pwd' not found", serr)
+	}
+	if "development" != str {
+		t.Error("Wrong value found :", str)
+	}
+}
+
+// Check LoadTOML
+func TestBuilder15(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	config, err := builder.LoadTOMLFile("testdata/config00.toml")
+
+	if nil != err {
+		t.Error("Load file  Failed", err)
+	}
+
+	// Tables are nested maps
+	dbconfig, serr := config.GetConfig("database")
+	if nil != serr {
+		t.Error("Key 'database' not found", serr)
+	}
+	port, serr := dbconfig.GetInt("port")
+	if nil != serr {
+		t.Error("Key 'port' not found", serr)
+	}
+	if 1234 != port {
+		t.Error("Wrong value found :", port)
+	}
+
+	// Native datetime
+	created, serr := config.GetTime("created")
+	if nil != serr {
+		t.Error("Key 'created' not found", serr)
+	}
+	if !created.Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)) {
+		t.Error("Wrong value found :", created)
+	}
+
+	// Arrays of tables are slices
+	raw, found, _ := config.(*ConfigImpl).get("servers")
+	if !found {
+		t.Error("Key 'servers' not found")
+	}
+	servers, ok := raw.([]interface{})
+	if !ok || 2 != len(servers) {
+		t.Error("Wrong value found :", raw)
+	}
+	if server, ok := servers[1].(map[string]interface{}); !ok || "beta" != server["name"] {
+		t.Error("Wrong value found :", servers[1])
+	}
+
+	// A table containing a datetime can still be read
+	_, serr = config.GetString("created")
+	if nil != serr {
+		t.Error("Key 'created' not found", serr)
+	}
+}
+
+// Check LoadTOML with invalid or missing file.
+func TestBuilder15b(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	_, err := builder.LoadTOMLFile("testdata/config01.toml")
+
+	if nil == err {
+		t.Error("Load file  should Fail")
+	}
+
+	_, err = builder.LoadTOMLFile("missing.toml")
+	if nil == err {
+		t.Error("Load missing file should Fail")
+	}
+}
+
+// Check multiple file parsing with toml file.
+func TestBuilder16(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "int")
+	config, err := builder.LoadFiles("testdata/config00.toml", "testdata/config00.txt")
+
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr := config.GetString("database.pwd")
+	if nil != serr {
+		t.Error("Key 'database.pwd' not found", serr)
+	}
+	if "integration" != str {
+		t.Error("Wrong value found :", str)
+	}
+}
+
+// Check LoadDir
+func TestBuilder17(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, err := builder.LoadDir("testdata/conf.d")
+
+	if nil != err {
+		t.Error("LoadDir Failed", err)
+	}
+
+	// First file wins
+	expected := map[string]string{
+		"name":          "base",
+		"database.port": "1234",
+		"database.host": "site.example.com",
+		"local":         "true",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+
+	// hidden files and sub directories are ignored
+	if _, serr := config.GetString("hidden"); nil == serr {
+		t.Error("Hidden file should be ignored")
+	}
+	if _, serr := config.GetString("sub"); nil == serr {
+		t.Error("Sub directory should be ignored")
+	}
+
+	// Missing directory
+	_, err = builder.LoadDir("testdata/nope.d")
+	if nil == err {
+		t.Error("LoadDir of missing directory should Fail")
+	}
+	builder.SetIgnoreMissingFiles(true)
+	_, err = builder.LoadDir("testdata/nope.d")
+	if nil != err {
+		t.Error("LoadDir of missing directory should be ignored", err)
+	}
+}
+
+// Check LoadGlob
+func TestBuilder18(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, err := builder.LoadGlob("testdata/conf.d/*.conf")
+
+	if nil != err {
+		t.Error("LoadGlob Failed", err)
+	}
+	str, serr := config.GetString("name")
+	if nil != serr || "local" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	if _, serr := config.GetString("database.port"); nil == serr {
+		t.Error("json file should not be loaded")
+	}
+	if _, serr := config.GetString("hidden"); nil == serr {
+		t.Error("Hidden file should be ignored")
+	}
+
+	// Nothing matches
+	_, err = builder.LoadGlob("testdata/conf.d/*.nope")
+	if nil != err {
+		t.Error("LoadGlob Failed", err)
+	}
+
+	// Bad pattern
+	_, err = builder.LoadGlob("testdata/conf.d/[")
+	if nil == err {
+		t.Error("LoadGlob should Fail")
+	}
+}
+
 // vi:set fileencoding=utf-8 tabstop=4 ai
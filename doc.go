@@ -40,6 +40,11 @@ Txt File Format
 	name = app name
 	database.url = user:${db.passwd}@/dbname
 	database.port = 3456
+	cert <<EOF
+	-----BEGIN CERTIFICATE-----
+	...
+	-----END CERTIFICATE-----
+	EOF
 
 */
 
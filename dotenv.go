@@ -0,0 +1,141 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadDotEnv Load a map from a .env Stream
+// merge loaded value with previous one.
+// Names are mapped to keys as env vars are : prefix is removed,
+// '_' are replaced with '.' and name is lowercased (CTX_DB_HOST => db.host).
+// Supported syntax : 'export' prefix, single quoted (raw) values,
+// double quoted values with escapes and on multiple lines, '#' comments.
+func (b *ConfigBuilder) LoadDotEnv(r io.Reader) (GoConfig, error) {
+	return b.loadDotEnv(r, "")
+}
+
+// loadDotEnv Load a map from a .env Stream read from filename (may be empty).
+func (b *ConfigBuilder) loadDotEnv(r io.Reader, filename string) (GoConfig, error) {
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); nil != err {
+		return b.conf, err
+	}
+
+	loaded := newSourceValues(SourceDotEnv, filename)
+	for i := 0; i < len(lines); i++ {
+		lineNb := i + 1
+		line := strings.TrimSpace(lines[i])
+		// ignore empty lines and comments
+		if "" == line || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") {
+			line = strings.TrimSpace(line[len("export "):])
+		}
+		// Parse key=value
+		words := strings.SplitN(line, "=", 2)
+		if len(words) != 2 {
+			return b.conf, &ParseError{file: filename, line: lineNb, msg: "missing '=' : '" + line + "'"}
+		}
+		name := strings.TrimSpace(words[0])
+		value := strings.TrimSpace(words[1])
+		switch {
+		case strings.HasPrefix(value, "\""):
+			// may span on several lines
+			raw := value[1:]
+			end := closingQuote(raw, '"')
+			for end < 0 && i+1 < len(lines) {
+				i++
+				raw += "\n" + lines[i]
+				end = closingQuote(raw, '"')
+			}
+			if end < 0 {
+				return b.conf, &ParseError{file: filename, line: lineNb, msg: "missing closing '\"' : '" + line + "'"}
+			}
+			value = unescapeDotEnv(raw[:end])
+		case strings.HasPrefix(value, "'"):
+			end := closingQuote(value[1:], '\'')
+			if end < 0 {
+				return b.conf, &ParseError{file: filename, line: lineNb, msg: "missing closing \"'\" : '" + line + "'"}
+			}
+			value = value[1 : end+1]
+		default:
+			// strip inline comments
+			if pos := strings.Index(value, " #"); pos >= 0 {
+				value = strings.TrimSpace(value[:pos])
+			}
+		}
+		// Set Value in a spare config
+		loaded.set(b.conf.def.keyFromEnv(name), value, filename, lineNb, b.mergePolicy)
+	}
+	// Merge new config and current one.
+	b.merge(loaded)
+
+	return b.conf, nil
+}
+
+// LoadDotEnvFile load from a file
+func (b *ConfigBuilder) LoadDotEnvFile(filename string) (GoConfig, error) {
+	f, err := os.Open(filename)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	return b.loadDotEnv(r, filename)
+}
+
+// closingQuote return position of the first unescaped quote or -1.
+// single quotes can not be escaped.
+func closingQuote(str string, quote byte) int {
+	for i := 0; i < len(str); i++ {
+		if '\\' == str[i] && '"' == quote {
+			i++
+		} else if quote == str[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDotEnv resolve escape sequences of double quoted values.
+func unescapeDotEnv(str string) string {
+	if !strings.Contains(str, "\\") {
+		return str
+	}
+	var buffer bytes.Buffer
+	for i := 0; i < len(str); i++ {
+		if '\\' != str[i] || i+1 >= len(str) {
+			buffer.WriteByte(str[i])
+			continue
+		}
+		i++
+		switch str[i] {
+		case 'n':
+			buffer.WriteByte('\n')
+		case 't':
+			buffer.WriteByte('\t')
+		case 'r':
+			buffer.WriteByte('\r')
+		default:
+			buffer.WriteByte(str[i])
+		}
+	}
+	return buffer.String()
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,105 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check dotenv parsing.
+func TestDotEnv0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "# comment\nexport CTX_KEY=value\nCTX_DB_HOST = localhost # inline comment\nDB_PORT=1234\n" +
+		"SINGLE='raw \\n # value'\nDOUBLE=\"escaped\\t\\\"value\\\"\" # comment\nMULTI=\"line1\nline2\"\n"
+	config, err := builder.LoadDotEnv(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadDotEnv Failed", err)
+	}
+
+	expected := map[string]string{
+		"key":     "value",
+		"db.host": "localhost",
+		"db.port": "1234",
+		"single":  "raw \\n # value",
+		"double":  "escaped\t\"value\"",
+		"multi":   "line1\nline2",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+}
+
+// Check dotenv parsing with syntax errors.
+func TestDotEnv1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	_, err := builder.LoadDotEnv(strings.NewReader("KEY=value\nNOPE\n"))
+	if nil == err {
+		t.Error("LoadDotEnv should Failed")
+	}
+	if perr, ok := err.(*ParseError); !ok || 2 != perr.line {
+		t.Error("Wrong error :", err)
+	}
+
+	_, err = builder.LoadDotEnv(strings.NewReader("KEY=value\nOTHER=\"open\nstill open\n"))
+	if nil == err {
+		t.Error("LoadDotEnv should Failed")
+	}
+	if perr, ok := err.(*ParseError); !ok || 2 != perr.line {
+		t.Error("Wrong error :", err)
+	}
+
+	_, err = builder.LoadDotEnv(strings.NewReader("KEY='value\n"))
+	if nil == err {
+		t.Error("LoadDotEnv should Failed")
+	}
+
+	_, err = builder.LoadDotEnvFile("testdata/invalid/config.env")
+	if perr, ok := err.(*ParseError); !ok || 2 != perr.line || "testdata/invalid/config.env" != perr.file {
+		t.Error("Wrong error :", err)
+	}
+}
+
+// Check dotenv files.
+func TestDotEnv2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	config, err := builder.LoadFiles("testdata/config00.env", "testdata/config00.txt")
+
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr := config.GetString("database.pwd")
+	if nil != serr {
+		t.Error("Key 'database.pwd' not found", serr)
+	}
+	if "development" != str {
+		t.Error("Wrong value found :", str)
+	}
+	str, serr = config.GetString("database.cert")
+	if nil != serr {
+		t.Error("Key 'database.cert' not found", serr)
+	}
+	if "-----BEGIN CERT-----\nabcdef\n-----END CERT-----" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	_, err = builder.LoadDotEnvFile("missing.env")
+	if nil == err {
+		t.Error("Load missing file should Fail")
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
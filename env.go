@@ -0,0 +1,63 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// SetEnvSeparator define separator of nested names in env vars, '_' by default.
+// With "__", CTX_DB__MAX_CONN is mapped to key 'db.max_conn'.
+func (b *ConfigBuilder) SetEnvSeparator(separator string) {
+	b.conf.def.envSeparator = separator
+}
+
+// EnvSeparator return separator of nested names in env vars
+func (b *ConfigBuilder) EnvSeparator() string {
+	return b.conf.def.separator()
+}
+
+// LoadEnv Load all env vars starting with prefix,
+// merge loaded value with previous one.
+// Names are mapped to keys : prefix is removed, separators are replaced
+// with '.' and name is lowercased (CTX_DB_HOST => db.host).
+// With an empty prefix all env vars are loaded.
+// When SetEnvFiles is enabled, <NAME>_FILE vars (of allowed keys) are read from files.
+func (b *ConfigBuilder) LoadEnv() (GoConfig, error) {
+	environ := os.Environ()
+	sort.Strings(environ)
+
+	loaded := newSourceValues(SourceEnv, "")
+	for _, entry := range environ {
+		words := strings.SplitN(entry, "=", 2)
+		if len(words) != 2 || !strings.HasPrefix(strings.ToUpper(words[0]), b.conf.def.prefix) {
+			continue
+		}
+		name, value := words[0], words[1]
+		source := Source{Kind: SourceEnv, Env: name}
+		if strings.HasSuffix(strings.ToUpper(name), envFileSuffix) &&
+			b.conf.def.isEnvFileKey(b.conf.def.keyFromEnv(name[:len(name)-len(envFileSuffix)])) {
+			secret, err := readSecret(value)
+			if nil != err {
+				return b.conf, &SecretFileError{env: name, err: err}
+			}
+			source = Source{Kind: SourceSecret, File: value, Env: name}
+			name, value = name[:len(name)-len(envFileSuffix)], secret
+		}
+		key := normalizeKey(b.conf.def.keyFromEnv(name))
+		if "" != key {
+			loaded.setFrom(key, value, source, b.mergePolicy)
+		}
+	}
+	// Merge new config and current one.
+	b.merge(loaded)
+
+	return b.conf, nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,99 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check LoadEnv
+func TestEnv0(t *testing.T) {
+	os.Setenv("LENV_NAME", "env name")
+	os.Setenv("LENV_DB_HOST", "env host")
+	os.Setenv("LENV_DB_PORT", "5432")
+	os.Setenv("OTHER_KEY", "other")
+
+	builder := NewBuilder("Lenv_", nil)
+	if "_" != builder.EnvSeparator() {
+		t.Error("Wrong separator", builder.EnvSeparator())
+	}
+	builder.LoadJSON(strings.NewReader("{ \"name\": \"json name\", \"db\": { \"user\": \"john\" } }"))
+	config, err := builder.LoadEnv()
+	if nil != err {
+		t.Error("LoadEnv Failed", err)
+	}
+
+	expected := map[string]string{
+		"name":    "json name",
+		"db.host": "env host",
+		"db.port": "5432",
+		"db.user": "john",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	if _, serr := config.GetString("other.key"); nil == serr {
+		t.Error("Key 'other.key' should be missing")
+	}
+
+	// sections can be extracted
+	sub, serr := config.GetConfig("db")
+	if nil != serr {
+		t.Error("Key 'db' not found", serr)
+	}
+	str, serr := sub.GetString("host")
+	if nil != serr || "env host" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+
+	source, _ := config.Origin("db.host")
+	if (Source{Kind: SourceEnv, Env: "LENV_DB_HOST"}) != source {
+		t.Error("Wrong source found :", source)
+	}
+}
+
+// Check LoadEnv with a separator
+func TestEnv1(t *testing.T) {
+	os.Setenv("SENV_DB__MAX_CONN", "10")
+	os.Setenv("SENV_LOG_LEVEL", "debug")
+	os.Setenv("SENV_HTTP__TLS__CERT_FILE", "cert.pem")
+
+	builder := NewBuilder("Senv_", nil)
+	builder.SetEnvSeparator("__")
+	builder.SetMergePolicy(LastWins)
+	builder.LoadJSON(strings.NewReader("{ \"log_level\": \"info\" }"))
+	config, err := builder.LoadEnv()
+	if nil != err {
+		t.Error("LoadEnv Failed", err)
+	}
+
+	expected := map[string]string{
+		"db.max_conn":        "10",
+		"log_level":          "debug",
+		"http.tls.cert_file": "cert.pem",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+
+	// lookups use the same separator
+	builder = NewBuilder("Senv_", nil)
+	builder.SetEnvSeparator("__")
+	str, serr := builder.Config().GetString("db.max_conn")
+	if nil != serr || "10" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
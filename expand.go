@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // matchEnd Find matching } of ${ in a string.
@@ -59,10 +60,13 @@ func (c *ConfigImpl) expandBuffer(buffer *bytes.Buffer, val string, deep uint) e
 			// Extra TrimSpace for keys.
 			key = strings.TrimSpace(key)
 			remain = remain[end+1:]
-			subs, exists := c.find(key)
+			subs, exists, err := c.find(key)
+			if nil != err {
+				return err
+			}
 			if exists && nil != subs {
 				// Convert found item into string
-				substr := fmt.Sprint(subs)
+				substr := fmt.Sprint(stripUnset(subs))
 				// enventually expand found value.
 				err = c.expandBuffer(buffer, substr, deep+1)
 				if err != nil {
@@ -174,7 +178,12 @@ func (c *ConfigImpl) translateRecursive(copy, original reflect.Value) {
 		copy.Set(copyValue)
 
 		// If it is a struct we translate each field
+		// time.Time has only unexported fields, take it as is.
 	case reflect.Struct:
+		if original.Type() == reflect.TypeOf(time.Time{}) {
+			copy.Set(original)
+			return
+		}
 		for i := 0; i < original.NumField(); i++ {
 			c.translateRecursive(copy.Field(i), original.Field(i))
 		}
@@ -188,9 +197,13 @@ func (c *ConfigImpl) translateRecursive(copy, original reflect.Value) {
 
 		// If it is a map we create a new map and translate each value
 	case reflect.Map:
+		// removed entries are skipped
 		copy.Set(reflect.MakeMap(original.Type()))
 		for _, key := range original.MapKeys() {
 			originalValue := original.MapIndex(key)
+			if isUnset(originalValue.Interface()) {
+				continue
+			}
 			// New gives us a pointer, but again we want the value
 			copyValue := reflect.New(originalValue.Type()).Elem()
 			c.translateRecursive(copyValue, originalValue)
@@ -0,0 +1,42 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"flag"
+)
+
+// RegisterFlags define a string flag for each key (i.e. --database.url),
+// current value of the key, if any, is used as default value of the flag.
+// Call LoadFlags once flags are parsed.
+func (b *ConfigBuilder) RegisterFlags(flags *flag.FlagSet, keys ...string) {
+	for _, key := range keys {
+		if nil == flags.Lookup(key) {
+			deflt, _ := b.conf.GetString(key, "")
+			flags.String(key, deflt, "value of '"+key+"'")
+		}
+	}
+}
+
+// LoadFlags load flags explicitly set on the command line, flag names are used as keys.
+// Flags override values already loaded, whatever the merge policy, and env vars
+// even with EnvOverFiles precedence, so they should be loaded after any other source.
+func (b *ConfigBuilder) LoadFlags(flags *flag.FlagSet) (GoConfig, error) {
+	loaded := newSourceValues(SourceFlag, "")
+	flags.Visit(func(f *flag.Flag) {
+		var value interface{} = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+		loaded.setFrom(f.Name, value, Source{Kind: SourceFlag}, LastWins)
+	})
+	// Merge new config and current one.
+	b.WithMergePolicy(LastWins).merge(loaded)
+
+	return b.conf, nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
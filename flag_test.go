@@ -0,0 +1,104 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Check RegisterFlags and LoadFlags.
+func TestFlag0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.LoadJSON(strings.NewReader("{ \"database\": { \"url\": \"json url\", \"user\": \"john\" }, \"debug\": false }"))
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	builder.RegisterFlags(flags, "database.url", "database.user", "name")
+	flags.Bool("debug", false, "debug mode")
+	flags.Duration("timeout", time.Second, "timeout")
+
+	if "json url" != flags.Lookup("database.url").DefValue {
+		t.Error("Wrong default value", flags.Lookup("database.url").DefValue)
+	}
+
+	err := flags.Parse([]string{"--database.url=flag url", "-debug", "--timeout", "3s"})
+	if nil != err {
+		t.Error("Parse Failed", err)
+	}
+	config, err := builder.LoadFlags(flags)
+	if nil != err {
+		t.Error("LoadFlags Failed", err)
+	}
+
+	expected := map[string]string{
+		"database.url":  "flag url",
+		"database.user": "john",
+		"debug":         "true",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	// flags not set are not loaded
+	if _, serr := config.GetString("name"); nil == serr {
+		t.Error("Key 'name' should be missing")
+	}
+	// typed flags
+	debug, serr := config.GetBool("debug")
+	if nil != serr || !debug {
+		t.Error("Wrong value found :", debug, serr)
+	}
+	timeout, serr := config.GetDuration("timeout")
+	if nil != serr || 3*time.Second != timeout {
+		t.Error("Wrong value found :", timeout, serr)
+	}
+
+	source, _ := config.Origin("database.url")
+	if SourceFlag != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+	if FirstWins != builder.MergePolicy() {
+		t.Error("Builder policy should not change", builder.MergePolicy())
+	}
+}
+
+// Check flags override env vars, even when env vars override files.
+func TestFlag1(t *testing.T) {
+	builder := NewBuilder("Flag1_", nil)
+	builder.SetEnvPrecedence(EnvOverFiles)
+	builder.LoadJSON(strings.NewReader("{ \"database\": { \"url\": \"json url\", \"user\": \"john\" } }"))
+	os.Setenv("FLAG1_DATABASE_URL", "env url")
+	defer os.Unsetenv("FLAG1_DATABASE_URL")
+	os.Setenv("FLAG1_DATABASE_USER", "jane")
+	defer os.Unsetenv("FLAG1_DATABASE_USER")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	builder.RegisterFlags(flags, "database.url", "database.user")
+	flags.Parse([]string{"--database.url=flag url"})
+	config, _ := builder.LoadFlags(flags)
+
+	if str, err := config.GetString("database.url"); nil != err || "flag url" != str {
+		t.Error("Wrong value found :", str, err)
+	}
+	if source, _ := config.Origin("database.url"); SourceFlag != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+	// flags not set do not hide env vars
+	if str, err := config.GetString("database.user"); nil != err || "jane" != str {
+		t.Error("Wrong value found :", str, err)
+	}
+	sub, _ := config.GetConfig("database")
+	if str, err := sub.GetString("url"); nil != err || "flag url" != str {
+		t.Error("Wrong sub config value found :", str, err)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
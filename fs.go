@@ -0,0 +1,64 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// LoadFilesFS load files from a file system (embed.FS, fstest.MapFS, zip, ...).
+// Guess file type by reading extension as LoadFiles does.
+// txt '@include' are resolved within the same file system.
+func (b *ConfigBuilder) LoadFilesFS(fsys fs.FS, filenames ...string) (GoConfig, error) {
+	return b.loadFiles(fsys, filenames)
+}
+
+// LoadDirFS load all files of a directory within a file system, in lexical order.
+// Sub directories and hidden files (starting with '.') are ignored,
+// once profiles are set, profile overlays are loaded only for active profiles.
+func (b *ConfigBuilder) LoadDirFS(fsys fs.FS, dirname string) (GoConfig, error) {
+	entries, err := fs.ReadDir(fsys, dirname)
+	if nil != err {
+		if b.ignoreMissingFiles && os.IsNotExist(err) {
+			return b.conf, nil
+		}
+		return nil, err
+	}
+	// ReadDir returns entries sorted by name
+	var filenames []string
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			filenames = append(filenames, path.Join(dirname, entry.Name()))
+		}
+	}
+	return b.loadFiles(fsys, b.dropProfileOverlays(filenames))
+}
+
+// LoadGlobFS load all files matching a pattern within a file system, in lexical order.
+// Directories and hidden files (starting with '.') are ignored,
+// once profiles are set, profile overlays are loaded only for active profiles.
+func (b *ConfigBuilder) LoadGlobFS(fsys fs.FS, pattern string) (GoConfig, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if nil != err {
+		return nil, err
+	}
+	// Glob returns matches sorted by name
+	var filenames []string
+	for _, filename := range matches {
+		if strings.HasPrefix(path.Base(filename), ".") {
+			continue
+		}
+		if info, err := fs.Stat(fsys, filename); nil == err && !info.IsDir() {
+			filenames = append(filenames, filename)
+		}
+	}
+	return b.loadFiles(fsys, b.dropProfileOverlays(filenames))
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
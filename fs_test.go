@@ -0,0 +1,119 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// testFS an in memory file system
+var testFS = fstest.MapFS{
+	"app.json":              {Data: []byte("{ \"name\": \"app\", \"database\": { \"port\": 1234 }}")},
+	"app.conf":              {Data: []byte("name = conf\n@include conf.d/*.conf\n@include? missing.conf\n")},
+	"conf.d/00-first.conf":  {Data: []byte("database.host = first\n")},
+	"conf.d/10-second.yaml": {Data: []byte("database:\n  user: john\n")},
+	"conf.d/.hidden.conf":   {Data: []byte("hidden = true\n")},
+	"conf.d/sub/ignored":    {Data: []byte("sub = true\n")},
+	"cycle.conf":            {Data: []byte("@include cycle.conf\n")},
+}
+
+// Check LoadFilesFS
+func TestFS0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, err := builder.LoadFilesFS(testFS, "app.json", "app.conf")
+
+	if nil != err {
+		t.Error("LoadFilesFS Failed", err)
+	}
+
+	expected := map[string]string{
+		"name":          "app",
+		"database.port": "1234",
+		"database.host": "first",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+
+	// Missing files
+	_, err = builder.LoadFilesFS(testFS, "nope.json")
+	if nil == err {
+		t.Error("LoadFilesFS of missing file should Fail")
+	}
+	builder.SetIgnoreMissingFiles(true)
+	_, err = builder.LoadFilesFS(testFS, "nope.json")
+	if nil != err {
+		t.Error("LoadFilesFS of missing file should be ignored", err)
+	}
+
+	// Cycles
+	_, err = builder.LoadFilesFS(testFS, "cycle.conf")
+	if nil == err {
+		t.Error("Include cycle should Fail")
+	}
+}
+
+// Check LoadDirFS and LoadGlobFS
+func TestFS1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, err := builder.LoadDirFS(testFS, "conf.d")
+
+	if nil != err {
+		t.Error("LoadDirFS Failed", err)
+	}
+	str, serr := config.GetString("database.user")
+	if nil != serr || "john" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	if _, serr := config.GetString("hidden"); nil == serr {
+		t.Error("Hidden file should be ignored")
+	}
+	if _, serr := config.GetString("sub"); nil == serr {
+		t.Error("Sub directory should be ignored")
+	}
+
+	builder = NewBuilder("Ctx_", nil)
+	config, err = builder.LoadGlobFS(testFS, "conf.d/*.conf")
+	if nil != err {
+		t.Error("LoadGlobFS Failed", err)
+	}
+	str, serr = config.GetString("database.host")
+	if nil != serr || "first" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	if _, serr := config.GetString("database.user"); nil == serr {
+		t.Error("yaml file should not be loaded")
+	}
+	if _, serr := config.GetString("hidden"); nil == serr {
+		t.Error("Hidden file should be ignored")
+	}
+
+	// Works with os file systems too
+	builder = NewBuilder("Ctx_", nil)
+	config, err = builder.LoadDirFS(os.DirFS("testdata"), "include/conf.d")
+	if nil != err {
+		t.Error("LoadDirFS Failed", err)
+	}
+	str, serr = config.GetString("db.user")
+	if nil != serr || "john" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+
+	_, err = builder.LoadDirFS(testFS, "nope.d")
+	if nil == err {
+		t.Error("LoadDirFS of missing directory should Fail")
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
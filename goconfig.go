@@ -7,6 +7,7 @@ package goconfig
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -20,29 +21,85 @@ type GoConfig interface {
 	GetFloat(key string, defaultValue ...interface{}) (float64, error)
 	GetBool(key string, deflt ...interface{}) (bool, error)
 	GetDuration(key string, deflt ...interface{}) (time.Duration, error)
+	GetTime(key string, deflt ...interface{}) (time.Time, error)
+	// Lists, from arrays or separated strings.
+	GetStringSlice(key string, deflt ...interface{}) ([]string, error)
+	GetIntSlice(key string, deflt ...interface{}) ([]int64, error)
+	GetDurationSlice(key string, deflt ...interface{}) ([]time.Duration, error)
+	// Sections.
+	Keys(prefix string) []string
+	Has(key string) bool
+	GetStringMap(key string) (map[string]interface{}, error)
+	GetStringMapString(key string) (map[string]string, error)
+	// Visit every leaf value.
+	Walk(fn func(key string, value interface{}) error) error
 	// GetString(key, deflt string) string
 	// GetBool(key string, deflt bool) bool
 	Expand(value string) (string, error)
+	// Active profiles, least specific first.
+	Profiles() []string
+	// Where the value of a key comes from.
+	Origin(key string, deflt ...interface{}) (Source, bool)
+	// Decode values of key (empty for whole config) into target, a pointer.
+	Unmarshal(key string, target interface{}) error
+	// Check if the value of key should be redacted.
+	IsSecret(key string) bool
 }
 
+// Redacted replace values of secret keys in Walk and map getters.
+const Redacted = "******"
+
+// EnvPrecedence define precedence of env vars over other sources.
+type EnvPrecedence int
+
+const (
+	// EnvLast env vars are searched after files and defaults (default).
+	EnvLast EnvPrecedence = iota
+	// EnvOverDefaults env vars are searched after files, but before defaults.
+	EnvOverDefaults
+	// EnvOverFiles env vars override files and defaults, but not flags nor arguments
+	// (see LoadFlags and LoadArgs).
+	EnvOverFiles
+)
+
 // ParseError Error for missing Key
 type ParseError struct {
+	file string
 	line int
+	arg  bool // line is the index of a command line argument
 	msg  string
 }
 
 // Error interface implementation
 func (m ParseError) Error() string {
+	if m.arg {
+		return fmt.Sprintf("Parse Error argument '%d' : '%s'", m.line, m.msg)
+	}
+	if "" != m.file {
+		return fmt.Sprintf("Parse Error file '%s' line '%d' : '%s'", m.file, m.line, m.msg)
+	}
 	return fmt.Sprintf("Parse Error line '%d' : '%s'", m.line, m.msg)
 }
 
 // MissingKeyError Error for missing Key
 type MissingKeyError struct {
-	key string
+	key  string
+	keys []string // all missing keys, when several are reported at once
+}
+
+// Keys return missing keys
+func (m MissingKeyError) Keys() []string {
+	if len(m.keys) > 0 {
+		return append([]string{}, m.keys...)
+	}
+	return []string{m.key}
 }
 
 // Error interface implementation
 func (m MissingKeyError) Error() string {
+	if len(m.keys) > 1 {
+		return fmt.Sprintf("Missing keys : '%s'", strings.Join(m.keys, "', '"))
+	}
 	return fmt.Sprintf("Missing key : '%s'", m.key)
 }
 
@@ -66,4 +123,40 @@ func (m ExpandRecursionError) Error() string {
 	return fmt.Sprintf("Expand key, max recursion reached : %d", m.step)
 }
 
+// SecretFileError Error while reading the file named by a <NAME>_FILE env var
+type SecretFileError struct {
+	env string
+	err error
+}
+
+// Error interface implementation
+func (m SecretFileError) Error() string {
+	return fmt.Sprintf("Secret file of '%s' : %s", m.env, m.err)
+}
+
+// Unwrap return the underlying error
+func (m SecretFileError) Unwrap() error {
+	return m.err
+}
+
+// UnmarshalError Error for values that could not be decoded
+type UnmarshalError struct {
+	keys []string // full keys of failing values
+	errs []error  // error of each key
+}
+
+// Keys return full keys of values that could not be decoded
+func (m UnmarshalError) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+// Error interface implementation
+func (m UnmarshalError) Error() string {
+	msgs := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		msgs[i] = fmt.Sprintf("'%s' : %s", key, m.errs[i])
+	}
+	return "Unmarshal Error " + strings.Join(msgs, ", ")
+}
+
 // vi:set fileencoding=utf-8 tabstop=4 ai
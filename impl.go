@@ -20,9 +20,18 @@ import (
 
 // ConfigDefault Store commons values
 type ConfigDefault struct {
-	prefix       string
-	values       map[string]interface{}
-	maxRecursion uint
+	prefix        string
+	values        map[string]interface{}
+	maxRecursion  uint
+	profiles      []string
+	origins       map[string]Source
+	envPrecedence EnvPrecedence
+	envSeparator  string
+	envFiles      bool
+	envFileKeys   map[string]bool // keys resolved with <NAME>_FILE vars, all when nil
+	secrets       map[string]bool
+	listSeparator string
+	listQuotes    string
 }
 
 // GetMaxRecursion return current max recursion.
@@ -43,36 +52,83 @@ func (c *ConfigDefault) GetPrefix() string {
 
 // GetValue try to get a value from defaults.
 // search first a value in default map, the into Env vars.
+// A secret file that can not be read is reported as missing, getters report the error.
 func (c *ConfigDefault) GetValue(key string) (interface{}, bool) {
-	found := false
-	var result interface{}
-
-	// try first default value
-	if nil != c.values {
-		keys := strings.Split(key, ".")
-		section := keys[:len(keys)-1]
-		// name is last part
-		name := keys[len(keys)-1]
+	result, _, found, err := c.getValue(key)
+	return result, found && nil == err
+}
 
-		m := subMap(&c.values, section, true)
-		if nil != m {
-			smap := *m
-			result, found = smap[name]
+// getValue search a value in default map, then into Env vars,
+// or the reverse when env vars override defaults.
+// return also where the value was found.
+func (c *ConfigDefault) getValue(key string) (interface{}, Source, bool, error) {
+	if EnvLast == c.envPrecedence {
+		if result, source, found := c.getDefault(key); found {
+			return result, source, true, nil
 		}
+		return c.getEnv(key)
 	}
-	if !found {
-		// try Env vars
-		name := c.prefix + key
-		// Convert to UpperCase but first replace '.' with '_'
-		name = strings.ToUpper(strings.Replace(name, ".", "_", -1))
-		result, found = os.LookupEnv(name)
-		if found {
-			return result, true
+	if result, source, found, err := c.getEnv(key); found {
+		return result, source, true, err
+	}
+	result, source, found := c.getDefault(key)
+	return result, source, found, nil
+}
+
+// getDefault search a value in default map.
+func (c *ConfigDefault) getDefault(key string) (interface{}, Source, bool) {
+	// never create missing sections, a getter does not update defaults
+	if result, found, _ := lookupKey(c.values, key); found {
+		return result, Source{Kind: SourceDefault}, true
+	}
+	return nil, Source{}, false
+}
+
+// getEnv search a value into Env vars.
+// When enabled, a <NAME>_FILE env var give the name of a file holding the value,
+// a file that can not be read is found, with an error.
+func (c *ConfigDefault) getEnv(key string) (interface{}, Source, bool, error) {
+	name := c.envName(key)
+	if result, found := os.LookupEnv(name); found {
+		return result, Source{Kind: SourceEnv, Env: name}, true, nil
+	}
+	if c.isEnvFileKey(key) {
+		if filename, found := os.LookupEnv(name + envFileSuffix); found {
+			source := Source{Kind: SourceSecret, File: filename, Env: name + envFileSuffix}
+			result, err := readSecret(filename)
+			if nil != err {
+				return nil, source, true, &SecretFileError{env: source.Env, err: err}
+			}
+			return result, source, true, nil
 		}
-		return nil, false
 	}
+	return nil, Source{}, false, nil
+}
 
-	return result, found
+// envName return the env var name for a key.
+func (c *ConfigDefault) envName(key string) string {
+	name := c.prefix + key
+	// Convert to UpperCase but first replace '.' with '_'
+	return strings.ToUpper(strings.Replace(name, ".", c.separator(), -1))
+}
+
+// separator return separator of nested names in env vars, '_' by default.
+func (c *ConfigDefault) separator() string {
+	if "" == c.envSeparator {
+		return "_"
+	}
+	return c.envSeparator
+}
+
+// keyFromEnv convert an env var name into a key.
+// Reverse of the mapping done by GetValue : prefix is removed,
+// separators ('_' by default) are replaced with '.' and name is lowercased.
+func (c *ConfigDefault) keyFromEnv(name string) string {
+	name = strings.ToUpper(name)
+	if "" != c.prefix && strings.HasPrefix(name, c.prefix) {
+		name = name[len(c.prefix):]
+	}
+	return strings.ToLower(strings.Replace(name, c.separator(), ".", -1))
 }
 
 // AddDefault Add a default value
@@ -96,25 +152,126 @@ func (c *ConfigDefault) AddDefault(key string, value interface{}) bool {
 	return false
 }
 
+// markSecret mark the value of key, and of its sub keys, as secret.
+func (c *ConfigDefault) markSecret(key string) {
+	if nil == c.secrets {
+		c.secrets = make(map[string]bool)
+	}
+	c.secrets[normalizeKey(key)] = true
+}
+
 // ConfigImpl implements GoConfig interface
 type ConfigImpl struct {
 	values map[string]interface{}
 	parent *ConfigImpl
 	def    *ConfigDefault
+	path   string // key of values within root config
 }
 
 // GetConfig Create a config using a subtree of the currents values
+// key may address an element of an array of objects (i.e. 'upstreams[0]' or 'upstreams.0').
 func (c *ConfigImpl) GetConfig(key string) (GoConfig, error) {
-	keys := strings.Split(key, ".")
-	values := subMap(&c.values, keys, false)
-	if nil == values {
+	var item interface{} = c.values
+	// Ignore empty keys !!
+	if trimmed := strings.Trim(key, ". "); "" != trimmed {
+		item, _, _ = lookupKey(c.values, trimmed)
+	}
+	values, ok := item.(map[string]interface{})
+	if !ok {
 		return nil, errors.New("Key '" + key + "' does not exsists")
 	}
-	return &ConfigImpl{values: *values, parent: c, def: c.def}, nil
+	return &ConfigImpl{values: values, parent: c, def: c.def, path: c.fullKey(key)}, nil
+}
+
+// envOverride search an env var overriding files values, using the full key.
+// Values given on the command line (see LoadFlags and LoadArgs) are not overridden.
+func (c *ConfigImpl) envOverride(key string) (interface{}, Source, bool, error) {
+	if EnvOverFiles != c.def.envPrecedence || c.fromCommandLine(key) {
+		return nil, Source{}, false, nil
+	}
+	return c.def.getEnv(c.fullKey(key))
+}
+
+// fromCommandLine check if the value of key was given by a flag or an argument.
+func (c *ConfigImpl) fromCommandLine(key string) bool {
+	switch c.def.origins[c.defaultKey(key)].Kind {
+	case SourceFlag, SourceCommandLine:
+		return true
+	}
+	return false
+}
+
+// fullKey return key as seen from root config.
+func (c *ConfigImpl) fullKey(key string) string {
+	if "" == c.path {
+		return normalizeKey(key)
+	}
+	return normalizeKey(c.path + "." + key)
+}
+
+// defaultKey return key as seen from root config, as stored by sources and defaults
+// (brackets are kept, see lookupKey).
+func (c *ConfigImpl) defaultKey(key string) string {
+	return storedKey(joinKey(c.path, key))
+}
+
+// Origin return where the value of key comes from, resolved as GetString does.
+// deflt, when given, is reported as SourceArgument if nothing else is found.
+func (c *ConfigImpl) Origin(key string, deflt ...interface{}) (Source, bool) {
+	if _, source, found, _ := c.envOverride(key); found {
+		return source, true
+	}
+	_, found, removed := lookupKey(c.values, key)
+	if found {
+		// a key holding brackets may be stored as is
+		if source, ok := c.def.origins[c.defaultKey(key)]; ok {
+			return source, true
+		}
+		// elements of arrays come from the source of the array
+		keys := splitKey(c.fullKey(key))
+		for i := len(keys); i > 0; i-- {
+			if source, ok := c.def.origins[strings.Join(keys[:i], ".")]; ok {
+				return source, true
+			}
+		}
+		return Source{Kind: SourceUnknown}, true
+	}
+	if !removed {
+		if _, source, found, _ := c.def.getValue(c.defaultKey(key)); found {
+			return source, true
+		}
+	}
+	if len(deflt) > 0 {
+		return Source{Kind: SourceArgument}, true
+	}
+	return Source{}, false
+}
+
+// IsSecret check if the value of key, or of one of its parents, was marked as secret
+// by a struct tag registered with AddDefaults, and so should be redacted.
+func (c *ConfigImpl) IsSecret(key string) bool {
+	keys := strings.Split(c.fullKey(key), ".")
+	for i := range keys {
+		if c.def.secrets[strings.Join(keys[:i+1], ".")] {
+			return true
+		}
+	}
+	return false
+}
+
+// Profiles return active profiles, least specific first.
+func (c *ConfigImpl) Profiles() []string {
+	return append([]string{}, c.def.profiles...)
 }
 
 // SetValue store a value (value may be a map[string]interface{})
+// An existing value is never overridden.
 func (c *ConfigImpl) SetValue(key string, value interface{}) bool {
+	return c.setValue(key, value, FirstWins)
+}
+
+// setValue store a value, an existing value is overridden according to policy.
+func (c *ConfigImpl) setValue(key string, value interface{}, policy MergePolicy) bool {
 	if nil != value {
 		keys := strings.Split(key, ".")
 		section := keys[:len(keys)-1]
@@ -122,19 +279,22 @@ func (c *ConfigImpl) SetValue(key string, value interface{}) bool {
 		name := strings.TrimSpace(keys[len(keys)-1])
 		entries := subMap(&c.values, section, true)
 		if nil != entries {
-			// Do Not override an existing entry
 			item, found := (*entries)[name]
 			if found {
 				// if value AND item are map[string]interface merge recursively
-				// otherwise do nothing
 				switch tsrc := value.(type) {
 				case map[string]interface{}:
 					switch tdest := item.(type) {
 					case map[string]interface{}:
-						mergeMap(tsrc, tdest)
+						mergeMap(tsrc, tdest, policy)
 						return true
 					}
 				}
+				// otherwise override only if last wins
+				if LastWins == policy {
+					(*entries)[name] = value
+					return true
+				}
 			} else {
 				(*entries)[name] = value
 				return true
@@ -169,17 +329,7 @@ func (c *ConfigImpl) GetBool(key string, defaultValue ...interface{}) (bool, err
 	raw, err := c.getExpand(key, defaultValue...)
 	// If not exists,
 	if nil != raw {
-		// Convert to string....
-		switch v := raw.(type) {
-		case bool:
-			return v, err
-		case string:
-			return strconv.ParseBool(v)
-		default:
-			// Convert to string
-			strval := fmt.Sprint(v)
-			return strconv.ParseBool(strval)
-		}
+		return toBool(raw)
 	}
 	return false, err
 }
@@ -190,59 +340,39 @@ func (c *ConfigImpl) GetDuration(key string, defaultValue ...interface{}) (time.
 	raw, err := c.getExpand(key, defaultValue...)
 	// If not exists,
 	if nil != raw {
-		// Convert to string....
-		switch v := raw.(type) {
-		case time.Duration:
-			return v, err
-		case string:
-			return time.ParseDuration(v)
-		default:
-			// Convert to string
-			strval := fmt.Sprint(v)
-			return time.ParseDuration(strval)
-		}
+		return toDuration(raw)
 	}
 	return 0 * time.Second, err
 }
 
+// timeLayouts formats accepted by GetTime when value is a string.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// GetTime read a Time from configuration.
+// strings are parsed as RFC3339, local datetime, local date or local time.
+func (c *ConfigImpl) GetTime(key string, defaultValue ...interface{}) (time.Time, error) {
+	// Get raw value
+	raw, err := c.getExpand(key, defaultValue...)
+	// If not exists,
+	if nil != raw {
+		return toTime(raw, key)
+	}
+	return time.Time{}, err
+}
+
 // GetInt read an Int from configuration.
 func (c *ConfigImpl) GetInt(key string, defaultValue ...interface{}) (int64, error) {
 	// Get raw value
 	raw, err := c.getExpand(key, defaultValue...)
 	// If not exists,
 	if nil != raw {
-		switch val := raw.(type) {
-		case int:
-			return int64(val), nil
-		case uint:
-			return int64(val), nil
-		case int8:
-			return int64(val), nil
-		case uint8:
-			return int64(val), nil
-		case int16:
-			return int64(val), nil
-		case uint16:
-			return int64(val), nil
-		case int32:
-			return int64(val), nil
-		case uint32:
-			return int64(val), nil
-		case int64:
-			return int64(val), nil
-		case uint64:
-			return int64(val), nil
-		case float32:
-			return int64(val), nil
-		case float64:
-			return int64(val), nil
-		case string:
-			return strconv.ParseInt(val, 0, 64)
-		default:
-			// Convert to string
-			strval := fmt.Sprint(val)
-			return strconv.ParseInt(strval, 0, 64)
-		}
+		return toInt(raw)
 	}
 	return 0, err
 }
@@ -253,38 +383,7 @@ func (c *ConfigImpl) GetUint(key string, defaultValue ...interface{}) (uint64, e
 	raw, err := c.getExpand(key, defaultValue...)
 	// If not exists,
 	if nil != raw {
-		switch val := raw.(type) {
-		case int:
-			return uint64(val), nil
-		case uint:
-			return uint64(val), nil
-		case int8:
-			return uint64(val), nil
-		case uint8:
-			return uint64(val), nil
-		case int16:
-			return uint64(val), nil
-		case uint16:
-			return uint64(val), nil
-		case int32:
-			return uint64(val), nil
-		case uint32:
-			return uint64(val), nil
-		case int64:
-			return uint64(val), nil
-		case uint64:
-			return uint64(val), nil
-		case float32:
-			return uint64(val), nil
-		case float64:
-			return uint64(val), nil
-		case string:
-			return strconv.ParseUint(val, 0, 64)
-		default:
-			// Convert to string
-			strval := fmt.Sprint(val)
-			return strconv.ParseUint(strval, 0, 64)
-		}
+		return toUint(raw)
 	}
 	return 0, err
 }
@@ -295,40 +394,162 @@ func (c *ConfigImpl) GetFloat(key string, defaultValue ...interface{}) (float64,
 	raw, err := c.getExpand(key, defaultValue...)
 	// If not exists,
 	if nil != raw {
-		switch val := raw.(type) {
-		case int:
-			return float64(val), nil
-		case uint:
-			return float64(val), nil
-		case int8:
-			return float64(val), nil
-		case uint8:
-			return float64(val), nil
-		case int16:
-			return float64(val), nil
-		case uint16:
-			return float64(val), nil
-		case int32:
-			return float64(val), nil
-		case uint32:
-			return float64(val), nil
-		case int64:
-			return float64(val), nil
-		case float32:
-			return float64(val), nil
-		case float64:
-			return float64(val), nil
-		case string:
-			return strconv.ParseFloat(val, 64)
-		default:
-			// Convert to string
-			strval := fmt.Sprint(val)
-			return strconv.ParseFloat(strval, 64)
-		}
+		return toFloat(raw)
 	}
 	return 0.0, err
 }
 
+// toBool convert a raw value into a boolean
+func toBool(raw interface{}) (bool, error) {
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		// Convert to string
+		strval := fmt.Sprint(v)
+		return strconv.ParseBool(strval)
+	}
+}
+
+// toDuration convert a raw value into a Duration, strings are parsed (i.e. "5s")
+func toDuration(raw interface{}) (time.Duration, error) {
+	switch v := raw.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(v)
+	default:
+		// Convert to string
+		strval := fmt.Sprint(v)
+		return time.ParseDuration(strval)
+	}
+}
+
+// toTime convert a raw value of key into a Time.
+func toTime(raw interface{}, key string) (time.Time, error) {
+	switch v := raw.(type) {
+	case time.Time:
+		return v, nil
+	default:
+		// Convert to string
+		strval := strings.TrimSpace(fmt.Sprint(v))
+		for _, layout := range timeLayouts {
+			if t, perr := time.Parse(layout, strval); nil == perr {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid time '%s' for key '%s'", strval, key)
+	}
+}
+
+// toInt convert a raw value into an int, strings are parsed (i.e. "0x10")
+func toInt(raw interface{}) (int64, error) {
+	switch val := raw.(type) {
+	case int:
+		return int64(val), nil
+	case uint:
+		return int64(val), nil
+	case int8:
+		return int64(val), nil
+	case uint8:
+		return int64(val), nil
+	case int16:
+		return int64(val), nil
+	case uint16:
+		return int64(val), nil
+	case int32:
+		return int64(val), nil
+	case uint32:
+		return int64(val), nil
+	case int64:
+		return int64(val), nil
+	case uint64:
+		return int64(val), nil
+	case float32:
+		return int64(val), nil
+	case float64:
+		return int64(val), nil
+	case string:
+		return strconv.ParseInt(val, 0, 64)
+	default:
+		// Convert to string
+		strval := fmt.Sprint(val)
+		return strconv.ParseInt(strval, 0, 64)
+	}
+}
+
+// toUint convert a raw value into an uint, strings are parsed (i.e. "0x10")
+func toUint(raw interface{}) (uint64, error) {
+	switch val := raw.(type) {
+	case int:
+		return uint64(val), nil
+	case uint:
+		return uint64(val), nil
+	case int8:
+		return uint64(val), nil
+	case uint8:
+		return uint64(val), nil
+	case int16:
+		return uint64(val), nil
+	case uint16:
+		return uint64(val), nil
+	case int32:
+		return uint64(val), nil
+	case uint32:
+		return uint64(val), nil
+	case int64:
+		return uint64(val), nil
+	case uint64:
+		return uint64(val), nil
+	case float32:
+		return uint64(val), nil
+	case float64:
+		return uint64(val), nil
+	case string:
+		return strconv.ParseUint(val, 0, 64)
+	default:
+		// Convert to string
+		strval := fmt.Sprint(val)
+		return strconv.ParseUint(strval, 0, 64)
+	}
+}
+
+// toFloat convert a raw value into a float
+func toFloat(raw interface{}) (float64, error) {
+	switch val := raw.(type) {
+	case int:
+		return float64(val), nil
+	case uint:
+		return float64(val), nil
+	case int8:
+		return float64(val), nil
+	case uint8:
+		return float64(val), nil
+	case int16:
+		return float64(val), nil
+	case uint16:
+		return float64(val), nil
+	case int32:
+		return float64(val), nil
+	case uint32:
+		return float64(val), nil
+	case int64:
+		return float64(val), nil
+	case float32:
+		return float64(val), nil
+	case float64:
+		return float64(val), nil
+	case string:
+		return strconv.ParseFloat(val, 64)
+	default:
+		// Convert to string
+		strval := fmt.Sprint(val)
+		return strconv.ParseFloat(strval, 64)
+	}
+}
+
 // subMap extract a sub part of the map.
 // if create is true an empty map will be created.
 // may return nil if create is false and no map is found or if the item found is not a map
@@ -364,31 +585,12 @@ func subMap(values *map[string]interface{}, keys []string, create bool) *map[str
 	return &vals
 }
 
-// mergeMap merge two maps. Copy all entries from the first to the second if
-func mergeMap(src, dest map[string]interface{}) {
-	// iterate over all key, values from src
-	for k, v := range src {
-		// search in dest value for same key
-		v2, found := dest[k]
-		if !found {
-			// not found : set it
-			dest[k] = v
-		} else {
-			// if v AND v2 are map[string]interface merge recursively
-			switch tsrc := v.(type) {
-			case map[string]interface{}:
-				switch tdest := v2.(type) {
-				case map[string]interface{}:
-					mergeMap(tsrc, tdest)
-				}
-			}
-		}
-	}
-}
-
 // getExpand return the stored value, or default, and expand if value is a string
 func (c *ConfigImpl) getExpand(key string, deflt ...interface{}) (raw interface{}, err error) {
-	result, found := c.get(key, deflt...)
+	result, found, err := c.get(key, deflt...)
+	if nil != err {
+		return nil, err
+	}
 	if !found {
 		return nil, &MissingKeyError{key: key}
 	}
@@ -403,51 +605,136 @@ func (c *ConfigImpl) getExpand(key string, deflt ...interface{}) (raw interface{
 }
 
 // get return the stored value as-is if exists
-func (c *ConfigImpl) get(key string, deflt ...interface{}) (raw interface{}, exists bool) {
-	keys := strings.Split(key, ".")
-	section := keys[:len(keys)-1]
-	// name is last part
-	name := keys[len(keys)-1]
-	entries := subMap(&c.values, section, false)
-	if nil != entries {
-		item, found := (*entries)[name]
-		if found {
-			return item, true
-		}
+// A key removed by a source (see unset) is not searched in defaults.
+// Defaults are searched with the key seen from root config.
+// err is set when a secret file can not be read.
+func (c *ConfigImpl) get(key string, deflt ...interface{}) (raw interface{}, exists bool, err error) {
+	if item, _, found, err := c.envOverride(key); found {
+		return item, true, err
 	}
-	// if nothing found try defaults
-	item, found := c.def.GetValue(key)
+	item, found, removed := lookupKey(c.values, key)
 	if found {
-		return item, true
+		return item, true, nil
+	}
+	if !removed {
+		// if nothing found try defaults
+		item, _, found, err := c.def.getValue(c.defaultKey(key))
+		if found {
+			return item, true, err
+		}
 	}
 	// fallback try default param
 	if len(deflt) > 0 {
-		return deflt[0], true
+		return deflt[0], true, nil
 	}
 	// Nothing found
-	return nil, false
+	return nil, false, nil
 }
 
 // find return the stored value, search eventualy in parents Config and Default.
-func (c *ConfigImpl) find(key string) (raw interface{}, exists bool) {
-	keys := strings.Split(key, ".")
-	section := keys[:len(keys)-1]
-	name := keys[len(keys)-1]
+// At each level, env vars overriding files are searched with the key seen from that level.
+func (c *ConfigImpl) find(key string) (raw interface{}, exists bool, err error) {
 	conf := c
-	var entries *map[string]interface{}
 	for conf != nil {
-		entries = subMap(&conf.values, section, false)
-		if entries != nil {
-			item, found := (*entries)[name]
-			if found {
-				return item, true
-			}
+		if item, _, found, err := conf.envOverride(key); found {
+			return item, true, err
+		}
+		item, found, removed := lookupKey(conf.values, key)
+		if found {
+			return item, true, nil
+		}
+		if removed {
+			return nil, false, nil
 		}
 		conf = conf.parent
 	}
 	// fail over, search in defaults
-	// first full name
-	return c.def.GetValue(key)
+	// with the key seen from each level, nearest first
+	for conf = c; conf != nil; conf = conf.parent {
+		if item, _, found, err := c.def.getValue(conf.defaultKey(key)); found {
+			return item, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+// lookupKey search the value of a dotted key. A key holding brackets is first searched
+// as stored by sources (i.e. 'servers[0]' in txt or '"a[1]"' in json), then as array indexes.
+func lookupKey(values map[string]interface{}, key string) (item interface{}, found bool, removed bool) {
+	if strings.Contains(key, "[") {
+		if item, found, removed = lookup(values, strings.Split(key, ".")); found || removed {
+			return item, found, removed
+		}
+	}
+	return lookup(values, splitKey(key))
+}
+
+// lookup search a value in nested maps, and arrays when a key is an index.
+// removed is true when the key, or one of its parents, was removed by a source.
+// An empty list of keys return values.
+func lookup(values map[string]interface{}, keys []string) (item interface{}, found bool, removed bool) {
+	item = values
+	for i, k := range keys {
+		k = strings.TrimSpace(k)
+		if "" == k && i < len(keys)-1 {
+			// Ignore empty keys !!
+			continue
+		}
+		switch container := item.(type) {
+		case map[string]interface{}:
+			item, found = container[k]
+		case []interface{}:
+			item, found = element(container, k)
+		default:
+			// Something else, int, string , ...
+			return nil, false, false
+		}
+		if !found {
+			return nil, false, false
+		}
+		if isUnset(item) {
+			return nil, false, true
+		}
+	}
+	return item, true, false
+}
+
+// element return the element of an array at index k.
+func element(values []interface{}, k string) (interface{}, bool) {
+	index, err := strconv.Atoi(k)
+	if nil != err || index < 0 || index >= len(values) {
+		return nil, false
+	}
+	return values[index], true
+}
+
+// splitKey split a dotted key, array indexes are separate parts :
+// 'servers[0].host' and 'servers.0.host' are both split into [servers 0 host].
+func splitKey(key string) []string {
+	parts := strings.Split(key, ".")
+	if !strings.Contains(key, "[") {
+		return parts
+	}
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		indexed := false
+		for open := strings.Index(part, "["); open >= 0; open = strings.Index(part, "[") {
+			end := strings.Index(part[open:], "]")
+			if end < 0 {
+				break
+			}
+			if name := strings.TrimSpace(part[:open]); "" != name {
+				result = append(result, name)
+			}
+			result = append(result, strings.TrimSpace(part[open+1:open+end]))
+			part = part[open+end+1:]
+			indexed = true
+		}
+		if !indexed || "" != strings.TrimSpace(part) {
+			result = append(result, part)
+		}
+	}
+	return result
 }
 
 // vi:set fileencoding=utf-8 tabstop=4 ai
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -206,6 +207,108 @@ func TestDuration0(t *testing.T) {
 
 }
 
+// Test Time
+func TestTime0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "{ \"t0\":\"2018-03-01T10:20:30Z\" , \"d0\":\"2018-03-01\", \"sub\": { \"int\":260 }}"
+	config, err := builder.LoadJSON(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+
+	val, serr := config.GetTime("t0")
+	if nil != serr {
+		t.Error("Key 't0' not found", serr)
+	}
+	if !val.Equal(time.Date(2018, 3, 1, 10, 20, 30, 0, time.UTC)) {
+		t.Error("Wrong value found :", val)
+	}
+
+	// local date
+	val, serr = config.GetTime("d0")
+	if nil != serr {
+		t.Error("Key 'd0' not found", serr)
+	}
+	if !val.Equal(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Wrong value found :", val)
+	}
+
+	// Value as int
+	_, serr = config.GetTime("sub.int")
+	if nil == serr {
+		t.Error("GetTime sub.int should fail")
+	}
+
+	// Missing value with a default value
+	deflt := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	val, serr = config.GetTime("sub.nope.key", deflt)
+	if nil != serr || !deflt.Equal(val) {
+		t.Error("Wrong value found :", val)
+	}
+
+	// Missing value without a default value
+	val, serr = config.GetTime("sub.nope.key")
+	if nil == serr {
+		t.Error("Should be error")
+	}
+	if !val.IsZero() {
+		t.Error("Wrong value found :", val)
+	}
+}
+
+// Test env vars precedence
+func TestEnvPrecedence0(t *testing.T) {
+	os.Setenv("CTX_PREC_FILE", "env file")
+	os.Setenv("CTX_PREC_DEFLT", "env default")
+	os.Setenv("CTX_PREC_SUB_KEY", "env sub")
+	str := "{ \"prec\": { \"file\": \"file\", \"sub\": { \"key\": \"file sub\" }, \"ref\": \"${prec.file}\" } }"
+
+	expected := map[EnvPrecedence][]string{
+		EnvLast:         {"file", "default", "file sub", "file"},
+		EnvOverDefaults: {"file", "env default", "file sub", "file"},
+		EnvOverFiles:    {"env file", "env default", "env sub", "env file"},
+	}
+	for precedence, values := range expected {
+		builder := NewBuilder("Ctx_", nil)
+		builder.AddDefault("prec.deflt", "default")
+		builder.SetEnvPrecedence(precedence)
+		if precedence != builder.EnvPrecedence() {
+			t.Error("Wrong precedence", builder.EnvPrecedence())
+		}
+		config, err := builder.LoadJSON(strings.NewReader(str))
+		if nil != err {
+			t.Error("LoadJSON Failed", err)
+		}
+		for i, key := range []string{"prec.file", "prec.deflt", "prec.sub.key", "prec.ref"} {
+			val, serr := config.GetString(key)
+			if nil != serr || values[i] != val {
+				t.Error("Wrong value found for", key, precedence, ":", val, serr)
+			}
+		}
+		// sub config use full key for env vars
+		sub, _ := config.GetConfig("prec.sub")
+		val, serr := sub.GetString("key")
+		if nil != serr || values[2] != val {
+			t.Error("Wrong value found for sub key", precedence, ":", val, serr)
+		}
+		// expansion search env vars with keys seen from parents
+		sub, _ = config.GetConfig("prec")
+		val, serr = sub.GetString("ref")
+		if nil != serr || values[3] != val {
+			t.Error("Wrong value found for sub ref", precedence, ":", val, serr)
+		}
+	}
+
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetEnvPrecedence(EnvOverFiles)
+	config, _ := builder.LoadJSON(strings.NewReader(str))
+	source, _ := config.Origin("prec.file")
+	if SourceEnv != source.Kind || "CTX_PREC_FILE" != source.Env {
+		t.Error("Wrong source found :", source)
+	}
+}
+
 // Test GetValue from default
 func TestDefault0(t *testing.T) {
 	// Create configDefault with nil default
@@ -442,6 +545,98 @@ func TestGetConfig0(t *testing.T) {
 
 }
 
+// Check array elements addressing.
+func TestGetConfig1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, err := builder.LoadJSON(strings.NewReader(`{
+		"domain": "example.com",
+		"upstreams": [
+			{ "host": "a.${domain}", "port": 80, "tags": [ "x", "y" ] },
+			{ "host": "b.${domain}", "port": 81, "url": "http://${host}:${port}/" }
+		],
+		"matrix": [ [ 1, 2 ], [ 3, 4 ] ],
+		"first": "${upstreams[0].host}"
+	}`))
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+
+	expected := map[string]string{
+		"upstreams[0].host":    "a.example.com",
+		"upstreams.1.port":     "81",
+		"upstreams[0].tags[1]": "y",
+		"upstreams.0.tags.0":   "x",
+		"matrix[1][0]":         "3",
+		"first":                "a.example.com",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	for _, key := range []string{"upstreams[2].host", "upstreams[-1].host", "upstreams[x].host", "domain[0]"} {
+		if _, serr := config.GetString(key); nil == serr {
+			t.Error("Key should be missing", key)
+		}
+	}
+
+	// iterate entries
+	upstreams, _ := config.GetStringSlice("upstreams")
+	for i := range upstreams {
+		upstream, serr := config.GetConfig("upstreams[" + strconv.Itoa(i) + "]")
+		if nil != serr {
+			t.Error("GetConfig Failed", i, serr)
+			continue
+		}
+		port, _ := upstream.GetInt("port")
+		if int64(80+i) != port {
+			t.Error("Wrong port", i, port)
+		}
+	}
+	upstream, _ := config.GetConfig("upstreams.1")
+	if url, serr := upstream.GetString("url"); nil != serr || "http://b.example.com:81/" != url {
+		t.Error("Wrong url", url, serr)
+	}
+	if _, serr := config.GetConfig("upstreams[0].tags"); nil == serr {
+		t.Error("GetConfig of a string array should fail")
+	}
+	if source, _ := config.Origin("upstreams[1].port"); SourceJSON != source.Kind {
+		t.Error("Wrong source", source)
+	}
+}
+
+// Check keys holding brackets stored as is by sources.
+func TestGetConfig2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.LoadTxt(strings.NewReader("servers[0] = x\n"))
+	config, _ := builder.LoadJSON(strings.NewReader(`{ "a[1]": "y", "sub": { "b[0]": { "c": "z" } } }`))
+
+	expected := map[string]string{
+		"servers[0]": "x",
+		"a[1]":       "y",
+		"sub.b[0].c": "z",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	sub, err := config.GetConfig("sub.b[0]")
+	if nil != err {
+		t.Error("GetConfig Failed", err)
+	} else if str, _ := sub.GetString("c"); "z" != str {
+		t.Error("Wrong value found :", str)
+	}
+	if source, _ := config.Origin("a[1]"); (Source{Kind: SourceJSON}) != source {
+		t.Error("Wrong source", source)
+	}
+	if source, _ := config.Origin("servers[0]"); (Source{Kind: SourceTxt, Line: 1}) != source {
+		t.Error("Wrong source", source)
+	}
+}
+
 // Check GetString. for nested string
 func TestGetUInt00(t *testing.T) {
 	str := "{ \"nope\": true, \"key\":\"value\", \"sub\": { \"key\":\"value\" }}"
@@ -0,0 +1,79 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadINI Load a map from an ini Stream
+// merge loaded value with previous one.
+// A [section] header prefix all following keys, i.e. key 'host' after
+// '[database.primary]' is stored as 'database.primary.host'.
+// Comments start with '#' or ';'.
+func (b *ConfigBuilder) LoadINI(r io.Reader) (GoConfig, error) {
+	return b.loadINI(r, "")
+}
+
+// loadINI Load a map from an ini Stream read from filename (may be empty).
+func (b *ConfigBuilder) loadINI(r io.Reader, filename string) (GoConfig, error) {
+
+	scanner := bufio.NewScanner(r)
+	lineNb := 0
+	section := ""
+	loaded := newSourceValues(SourceINI, filename)
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		// ignore empty lines and comments
+		if "" == line || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		// Section header
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return b.conf, &ParseError{file: filename, line: lineNb, msg: "missing ']' : '" + line + "'"}
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		// Parse key=value
+		words := strings.SplitN(line, "=", 2)
+		if len(words) != 2 {
+			return b.conf, &ParseError{file: filename, line: lineNb, msg: "missing '=' : '" + line + "'"}
+		}
+		key := strings.TrimSpace(words[0])
+		value := strings.TrimSpace(words[1])
+		if "" != section {
+			key = section + "." + key
+		}
+		// Set Value in a spare config
+		loaded.set(key, value, filename, lineNb, b.mergePolicy)
+	}
+	if err := scanner.Err(); nil != err {
+		return b.conf, err
+	}
+	// Merge new config and current one.
+	b.merge(loaded)
+
+	return b.conf, nil
+}
+
+// LoadINIFile load from a file
+func (b *ConfigBuilder) LoadINIFile(filename string) (GoConfig, error) {
+	f, err := os.Open(filename)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	return b.loadINI(r, filename)
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,119 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check ini parsing with sections.
+func TestINI0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "; comment\nkey = value\n[sub]\n# other comment\nkey = sub value\n[sub.deep]\nport=1234\n[]\nglobal = yes\n"
+	config, err := builder.LoadINI(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadINI Failed", err)
+	}
+
+	str, serr := config.GetString("key")
+	if nil != serr {
+		t.Error("Key 'key' not found", serr)
+	}
+	if "value" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	str, serr = config.GetString("sub.key")
+	if nil != serr {
+		t.Error("Key 'sub.key' not found", serr)
+	}
+	if "sub value" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	port, serr := config.GetInt("sub.deep.port")
+	if nil != serr {
+		t.Error("Key 'sub.deep.port' not found", serr)
+	}
+	if 1234 != port {
+		t.Error("Wrong value found :", port)
+	}
+
+	// Empty section reset to top level
+	str, serr = config.GetString("global")
+	if nil != serr {
+		t.Error("Key 'global' not found", serr)
+	}
+	if "yes" != str {
+		t.Error("Wrong value found :", str)
+	}
+}
+
+// Check ini parsing with syntax errors.
+func TestINI1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	_, err := builder.LoadINI(strings.NewReader("[sub\nkey = value\n"))
+	if nil == err {
+		t.Error("LoadINI should Failed")
+	}
+	if perr, ok := err.(*ParseError); !ok || 1 != perr.line {
+		t.Error("Wrong error :", err)
+	}
+
+	_, err = builder.LoadINI(strings.NewReader("[sub]\nkey\n"))
+	if nil == err {
+		t.Error("LoadINI should Failed")
+	}
+	if perr, ok := err.(*ParseError); !ok || 2 != perr.line {
+		t.Error("Wrong error :", err)
+	}
+
+	_, err = builder.LoadINIFile("testdata/invalid/config.ini")
+	if perr, ok := err.(*ParseError); !ok || 2 != perr.line || "testdata/invalid/config.ini" != perr.file {
+		t.Error("Wrong error :", err)
+	}
+}
+
+// Check ini files.
+func TestINI2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	config, err := builder.LoadFiles("testdata/config00.ini", "testdata/config00.txt")
+
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr := config.GetString("database.primary.host")
+	if nil != serr {
+		t.Error("Key 'database.primary.host' not found", serr)
+	}
+	if "db1.example.com" != str {
+		t.Error("Wrong value found :", str)
+	}
+	str, serr = config.GetString("database.pwd")
+	if nil != serr {
+		t.Error("Key 'database.pwd' not found", serr)
+	}
+	if "development" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	_, err = builder.LoadINIFile("testdata/config01.ini")
+	if nil == err {
+		t.Error("Load file  should Fail")
+	}
+
+	_, err = builder.LoadINIFile("missing.ini")
+	if nil == err {
+		t.Error("Load missing file should Fail")
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
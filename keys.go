@@ -0,0 +1,194 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"fmt"
+	"sort"
+)
+
+// section return values of a section (whole config when key is empty),
+// merged with defaults of the key seen from root config (see defaultKey).
+// Removed keys are skipped, nested maps are copied.
+func (c *ConfigImpl) section(key string) (map[string]interface{}, bool) {
+	var own, deflt map[string]interface{}
+	removed := false
+	if "" == normalizeKey(key) {
+		own = c.values
+	} else {
+		var item interface{}
+		item, _, removed = lookupKey(c.values, key)
+		own, _ = item.(map[string]interface{})
+	}
+	if full := c.defaultKey(key); "" == full {
+		deflt = c.def.values
+	} else if !removed {
+		item, _, _ := lookupKey(c.def.values, full)
+		deflt, _ = item.(map[string]interface{})
+	}
+	if nil == own && nil == deflt {
+		return nil, false
+	}
+	result := make(map[string]interface{})
+	overlaySection(result, deflt)
+	overlaySection(result, own)
+	return result, true
+}
+
+// overlaySection copy values of src into dest, removed keys are deleted from dest.
+func overlaySection(dest, src map[string]interface{}) {
+	for k, v := range src {
+		if isUnset(v) {
+			delete(dest, k)
+			continue
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			sub, ok := dest[k].(map[string]interface{})
+			if !ok {
+				// dest only hold copies, that may be updated
+				sub = make(map[string]interface{})
+				dest[k] = sub
+			}
+			overlaySection(sub, m)
+			continue
+		}
+		dest[k] = v
+	}
+}
+
+// walkSection call fn for each leaf of values, in lexical order of keys.
+// Keys are dotted keys, starting with prefix.
+func walkSection(values map[string]interface{}, prefix string, fn func(key string, value interface{}) error) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := joinKey(prefix, name)
+		if m, ok := values[name].(map[string]interface{}); ok {
+			if err := walkSection(m, key, fn); nil != err {
+				return err
+			}
+			continue
+		}
+		if err := fn(key, values[name]); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve return a copy of a section (whole config when key is empty), each leaf
+// is resolved as getters do (env vars may override it) and expanded.
+// Values of secret keys are Redacted.
+func (c *ConfigImpl) resolve(key string) (map[string]interface{}, bool, error) {
+	values, found := c.section(key)
+	if !found {
+		return nil, false, nil
+	}
+	return values, true, c.resolveSection(values, normalizeKey(key))
+}
+
+// resolveSection replace leaves of values, a copy of section key, with their resolved values.
+func (c *ConfigImpl) resolveSection(values map[string]interface{}, key string) error {
+	for name, value := range values {
+		entry := joinKey(key, name)
+		if c.IsSecret(entry) {
+			values[name] = Redacted
+			continue
+		}
+		if m, ok := value.(map[string]interface{}); ok {
+			if err := c.resolveSection(m, entry); nil != err {
+				return err
+			}
+			continue
+		}
+		item, found, err := c.get(entry)
+		if nil != err {
+			return err
+		}
+		if found {
+			value = item
+		}
+		if str, ok := value.(string); ok {
+			if value, err = c.Expand(str); nil != err {
+				return err
+			}
+		} else if nil != value {
+			value = c.Translate(value)
+		}
+		values[name] = value
+	}
+	return nil
+}
+
+// Keys return names of the entries of a section (whole config when prefix is empty),
+// including defaults, in lexical order, i.e. Keys("databases") => [main replica].
+func (c *ConfigImpl) Keys(prefix string) []string {
+	values, _ := c.section(prefix)
+	result := make([]string, 0, len(values))
+	for name := range values {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Has check if a value exists for key, in values, defaults or env vars.
+func (c *ConfigImpl) Has(key string) bool {
+	_, found, _ := c.get(key)
+	return found
+}
+
+// GetStringMap return a copy of a section, merged with defaults, values are resolved
+// as getters do (env vars may override them) and strings are expanded.
+// Values of secret keys are Redacted.
+func (c *ConfigImpl) GetStringMap(key string) (map[string]interface{}, error) {
+	values, found, err := c.resolve(key)
+	if nil != err {
+		return nil, err
+	}
+	if !found {
+		return nil, &MissingKeyError{key: key}
+	}
+	return values, nil
+}
+
+// GetStringMapString return values of a section as strings, resolved as GetStringMap does.
+// Nested values are flattened using dotted keys (i.e. "db.port").
+func (c *ConfigImpl) GetStringMapString(key string) (map[string]string, error) {
+	values, found, err := c.resolve(key)
+	if nil != err {
+		return nil, err
+	}
+	if !found {
+		return nil, &MissingKeyError{key: key}
+	}
+	result := make(map[string]string)
+	walkSection(values, "", func(name string, value interface{}) error {
+		result[name] = fmt.Sprint(value)
+		return nil
+	})
+	return result, nil
+}
+
+// Walk call fn for each leaf value (arrays are leaves), in lexical order of keys,
+// values of defaults are visited when not overridden.
+// Keys are full dotted keys, as seen from root config, values are resolved as getters do
+// (env vars may override them) and strings are expanded. Values of secret keys are Redacted.
+// Walk stop at first error returned by fn.
+func (c *ConfigImpl) Walk(fn func(key string, value interface{}) error) error {
+	values, _, err := c.resolve("")
+	if nil != err {
+		return err
+	}
+	return walkSection(values, "", func(key string, value interface{}) error {
+		return fn(c.fullKey(key), value)
+	})
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,212 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newKeysConfig return a config with defaults, values and removed keys.
+func newKeysConfig() GoConfig {
+	builder := NewBuilder("Ctx_", map[string]interface{}{
+		"databases": map[string]interface{}{
+			"main":   map[string]interface{}{"port": 5432, "pool": 10},
+			"legacy": map[string]interface{}{"port": 3306},
+		},
+		"timeout": "5s",
+	})
+	config, _ := builder.LoadJSON(strings.NewReader(`{
+		"name": "app",
+		"databases": {
+			"main": { "host": "${name}.db" },
+			"replica": { "host": "replica.db", "port": 5433 },
+			"legacy": null
+		},
+		"flags": { "beta": true, "new_ui": false, "ui": { "dark": "on" } },
+		"servers": [ "a", "b" ]
+	}`))
+	return config
+}
+
+// Check Keys, Has and map getters.
+func TestKeys0(t *testing.T) {
+	config := newKeysConfig()
+
+	keys := config.Keys("databases")
+	if "main,replica" != strings.Join(keys, ",") {
+		t.Error("Wrong databases keys", keys)
+	}
+	keys = config.Keys("")
+	if "databases,flags,name,servers,timeout" != strings.Join(keys, ",") {
+		t.Error("Wrong root keys", keys)
+	}
+	if 0 != len(config.Keys("name")) || 0 != len(config.Keys("missing")) {
+		t.Error("No keys expected")
+	}
+
+	if !config.Has("databases.main.pool") || !config.Has("flags.beta") || config.Has("databases.legacy.port") || config.Has("missing") {
+		t.Error("Wrong Has result")
+	}
+
+	main, err := config.GetStringMap("databases.main")
+	if nil != err || "app.db" != main["host"] || 5432 != main["port"] || 3 != len(main) {
+		t.Error("Wrong map", main, err)
+	}
+	flags, err := config.GetStringMapString("flags")
+	if nil != err || "true" != flags["beta"] || "false" != flags["new_ui"] || "on" != flags["ui.dark"] || 3 != len(flags) {
+		t.Error("Wrong flags", flags, err)
+	}
+	if _, err = config.GetStringMap("missing"); nil == err {
+		t.Error("Missing section should fail")
+	}
+
+	sub, _ := config.GetConfig("databases")
+	if "main,replica" != strings.Join(sub.Keys(""), ",") {
+		t.Error("Wrong sub config keys", sub.Keys(""))
+	}
+}
+
+// Check Walk.
+func TestKeys1(t *testing.T) {
+	config := newKeysConfig()
+	sub, _ := config.GetConfig("databases")
+
+	var keys []string
+	values := make(map[string]interface{})
+	err := sub.Walk(func(key string, value interface{}) error {
+		keys = append(keys, key)
+		values[key] = value
+		return nil
+	})
+	if nil != err {
+		t.Error("Walk Failed", err)
+	}
+	expected := "databases.main.host,databases.main.pool,databases.main.port,databases.replica.host,databases.replica.port"
+	if expected != strings.Join(keys, ",") {
+		t.Error("Wrong walked keys", keys)
+	}
+	if "app.db" != values["databases.main.host"] {
+		t.Error("Value should be expanded", values["databases.main.host"])
+	}
+
+	count := 0
+	stop := errors.New("stop")
+	err = config.Walk(func(key string, value interface{}) error {
+		count++
+		if "servers" == key {
+			if servers, ok := value.([]interface{}); !ok || 2 != len(servers) {
+				t.Error("Wrong servers", value)
+			}
+			return stop
+		}
+		return nil
+	})
+	if stop != err || 10 != count {
+		t.Error("Walk should stop on error", count, err)
+	}
+}
+
+// Check secret values are redacted.
+func TestKeys2(t *testing.T) {
+	type credentials struct {
+		User     string `config:"user"`
+		Password string `config:"password,secret"`
+	}
+	type app struct {
+		DB   credentials       `config:"db"`
+		Keys map[string]string `config:"keys,secret"`
+	}
+	builder := NewBuilder("Ctx_", nil)
+	builder.AddDefaults("", &app{})
+	config, _ := builder.LoadJSON(strings.NewReader(`{ "db": { "user": "john", "password": "p@ss" }, "keys": { "a": "k1" } }`))
+
+	db, err := config.GetStringMap("db")
+	if nil != err || Redacted != db["password"] || "john" != db["user"] {
+		t.Error("Wrong map", db, err)
+	}
+	all, err := config.GetStringMapString("")
+	if nil != err || Redacted != all["db.password"] || Redacted != all["keys"] || "john" != all["db.user"] {
+		t.Error("Wrong flattened map", all, err)
+	}
+	values := make(map[string]interface{})
+	config.Walk(func(key string, value interface{}) error {
+		values[key] = value
+		return nil
+	})
+	if Redacted != values["db.password"] || Redacted != values["keys"] || "john" != values["db.user"] {
+		t.Error("Wrong walked values", values)
+	}
+	// getters still return values
+	if str, _ := config.GetString("db.password"); "p@ss" != str {
+		t.Error("Wrong value found :", str)
+	}
+}
+
+// Check a sub config reads defaults as it lists them, and getters do not update defaults.
+func TestKeys3(t *testing.T) {
+	config := newKeysConfig()
+	sub, err := config.GetConfig("databases.main")
+	if nil != err {
+		t.Fatal("Sub config expected", err)
+	}
+	if "host,pool,port" != strings.Join(sub.Keys(""), ",") {
+		t.Error("Wrong sub config keys", sub.Keys(""))
+	}
+	if !sub.Has("pool") {
+		t.Error("pool should exist")
+	}
+	if str, err := sub.GetString("pool"); nil != err || "10" != str {
+		t.Error("Wrong pool", str, err)
+	}
+	if str, err := sub.GetString("pool", 2); nil != err || "10" != str {
+		t.Error("Wrong pool with default", str, err)
+	}
+	values, err := sub.GetStringMap("")
+	if nil != err || 10 != values["pool"] || "app.db" != values["host"] {
+		t.Error("Wrong sub config map", values, err)
+	}
+
+	if _, err = config.GetString("ghost.sub.key"); nil == err {
+		t.Error("ghost.sub.key should not exist")
+	}
+	if config.Has("ghost") || "databases,flags,name,servers,timeout" != strings.Join(config.Keys(""), ",") {
+		t.Error("Getters should not create sections", config.Keys(""))
+	}
+}
+
+// Check map getters use env vars overriding files.
+func TestKeys4(t *testing.T) {
+	builder := NewBuilder("Keys4_", nil)
+	builder.SetEnvPrecedence(EnvOverFiles)
+	builder.SetEnvFiles(true)
+	config, _ := builder.LoadJSON(strings.NewReader(`{ "db": { "host": "file", "port": 5432, "password": "p" } }`))
+
+	os.Setenv("KEYS4_DB_HOST", "envhost")
+	defer os.Unsetenv("KEYS4_DB_HOST")
+	db, err := config.GetStringMap("db")
+	if nil != err || "envhost" != db["host"] || float64(5432) != db["port"] {
+		t.Error("Wrong map", db, err)
+	}
+	flat, err := config.GetStringMapString("")
+	if nil != err || "envhost" != flat["db.host"] {
+		t.Error("Wrong flattened map", flat, err)
+	}
+
+	os.Setenv("KEYS4_DB_PASSWORD_FILE", "testdata/missing_secret")
+	defer os.Unsetenv("KEYS4_DB_PASSWORD_FILE")
+	var serr *SecretFileError
+	if _, err = config.GetStringMap("db"); !errors.As(err, &serr) {
+		t.Error("SecretFileError expected", err)
+	}
+	if _, err = config.GetStringMapString("db"); !errors.As(err, &serr) {
+		t.Error("SecretFileError expected", err)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,188 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultListSeparator separator of list items within a string.
+	defaultListSeparator = ","
+	// defaultListQuotes quotes of list items holding a separator.
+	defaultListQuotes = "\"'"
+)
+
+// SetListSeparator define separator of list items within a string, ',' by default.
+func (b *ConfigBuilder) SetListSeparator(separator string) {
+	b.conf.def.listSeparator = separator
+}
+
+// ListSeparator return separator of list items within a string
+func (b *ConfigBuilder) ListSeparator() string {
+	return b.conf.def.getListSeparator()
+}
+
+// SetListQuotes define quotes of list items holding a separator (i.e. a, "b, c"),
+// double and single quotes by default. An empty string disable quoting.
+func (b *ConfigBuilder) SetListQuotes(quotes string) {
+	b.conf.def.listQuotes = quotes
+}
+
+// ListQuotes return quotes of list items
+func (b *ConfigBuilder) ListQuotes() string {
+	return b.conf.def.listQuotes
+}
+
+// getListSeparator return separator of list items, ',' by default.
+func (c *ConfigDefault) getListSeparator() string {
+	if "" == c.listSeparator {
+		return defaultListSeparator
+	}
+	return c.listSeparator
+}
+
+// splitList split a string into items, items are trimmed and may be quoted.
+// An empty string is an empty list.
+func (c *ConfigDefault) splitList(value string) ([]interface{}, error) {
+	separator := c.getListSeparator()
+	result := []interface{}{}
+	remain := strings.TrimSpace(value)
+	if "" == remain {
+		return result, nil
+	}
+	for {
+		var item string
+		if "" != remain && strings.ContainsRune(c.listQuotes, rune(remain[0])) {
+			end := strings.IndexByte(remain[1:], remain[0])
+			if end < 0 {
+				return nil, errors.New("missing closing quote : '" + value + "'")
+			}
+			item = remain[1 : end+1]
+			remain = strings.TrimSpace(remain[end+2:])
+			if "" != remain && !strings.HasPrefix(remain, separator) {
+				return nil, errors.New("unexpected chars after quote : '" + value + "'")
+			}
+		} else if pos := strings.Index(remain, separator); pos >= 0 {
+			item = strings.TrimSpace(remain[:pos])
+			remain = remain[pos:]
+		} else {
+			item = remain
+			remain = ""
+		}
+		result = append(result, item)
+		if "" == remain {
+			return result, nil
+		}
+		remain = strings.TrimSpace(remain[len(separator):])
+	}
+}
+
+// getList return the items of a list, stored as an array or as a separated string.
+// A scalar is a single item list.
+func (c *ConfigImpl) getList(key string, deflt ...interface{}) ([]interface{}, error) {
+	raw, found, err := c.get(key, deflt...)
+	if nil != err {
+		return nil, err
+	}
+	if !found {
+		return nil, &MissingKeyError{key: key}
+	}
+	return c.toList(raw)
+}
+
+// toList convert a raw value into a list.
+func (c *ConfigImpl) toList(raw interface{}) ([]interface{}, error) {
+	switch v := raw.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case []interface{}:
+		return v, nil
+	case string:
+		return c.def.splitList(v)
+	}
+	value := reflect.ValueOf(raw)
+	if reflect.Slice == value.Kind() || reflect.Array == value.Kind() {
+		result := make([]interface{}, value.Len())
+		for i := range result {
+			result[i] = value.Index(i).Interface()
+		}
+		return result, nil
+	}
+	return []interface{}{raw}, nil
+}
+
+// expandItem expand an item of a list when it is a string.
+func (c *ConfigImpl) expandItem(item interface{}) (interface{}, error) {
+	if str, ok := item.(string); ok {
+		return c.Expand(str)
+	}
+	return item, nil
+}
+
+// GetStringSlice read a list of strings, from an array or a separated string (a, b, "c, d").
+// Each item is expanded.
+func (c *ConfigImpl) GetStringSlice(key string, deflt ...interface{}) ([]string, error) {
+	items, err := c.getList(key, deflt...)
+	if nil != err {
+		return nil, err
+	}
+	result := make([]string, len(items))
+	for i, item := range items {
+		value, err := c.expandItem(item)
+		if nil != err {
+			return nil, err
+		}
+		result[i] = fmt.Sprint(value)
+	}
+	return result, nil
+}
+
+// GetIntSlice read a list of ints, from an array or a separated string (1, 0x10).
+// Each item is expanded and converted as GetInt does.
+func (c *ConfigImpl) GetIntSlice(key string, deflt ...interface{}) ([]int64, error) {
+	items, err := c.getList(key, deflt...)
+	if nil != err {
+		return nil, err
+	}
+	result := make([]int64, len(items))
+	for i, item := range items {
+		value, err := c.expandItem(item)
+		if nil == err {
+			result[i], err = toInt(value)
+		}
+		if nil != err {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetDurationSlice read a list of Durations, from an array or a separated string (1s, 5m).
+// Each item is expanded and converted as GetDuration does.
+func (c *ConfigImpl) GetDurationSlice(key string, deflt ...interface{}) ([]time.Duration, error) {
+	items, err := c.getList(key, deflt...)
+	if nil != err {
+		return nil, err
+	}
+	result := make([]time.Duration, len(items))
+	for i, item := range items {
+		value, err := c.expandItem(item)
+		if nil == err {
+			result[i], err = toDuration(value)
+		}
+		if nil != err {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,107 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Check list getters with arrays and separated strings.
+func TestList0(t *testing.T) {
+	os.Setenv("CTX_ENV_HOSTS", "e1, e2")
+	defer os.Unsetenv("CTX_ENV_HOSTS")
+	builder := NewBuilder("Ctx_", nil)
+	builder.LoadTxt(strings.NewReader(`
+host = example.com
+hosts = a, ${host} , "b, c", 'd'
+ports = 80, 0x10
+timeouts = 1s, 5m
+empty =
+bad = "a, b
+`))
+	config, err := builder.LoadJSON(strings.NewReader(`{ "array": [ "x", "${host}", 3 ], "nums": [ 1, "2" ], "delays": [ "1s", "2s" ] }`))
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+
+	tests := []struct {
+		key      string
+		expected []string
+	}{
+		{"hosts", []string{"a", "example.com", "b, c", "d"}},
+		{"array", []string{"x", "example.com", "3"}},
+		{"env.hosts", []string{"e1", "e2"}},
+		{"empty", []string{}},
+		{"host", []string{"example.com"}},
+	}
+	for _, test := range tests {
+		values, err := config.GetStringSlice(test.key)
+		if nil != err || len(test.expected) != len(values) {
+			t.Error("Wrong values for", test.key, values, err)
+			continue
+		}
+		for i, value := range test.expected {
+			if value != values[i] {
+				t.Error("Wrong value for", test.key, i, values[i])
+			}
+		}
+	}
+
+	ints, err := config.GetIntSlice("ports")
+	if nil != err || 2 != len(ints) || 80 != ints[0] || 16 != ints[1] {
+		t.Error("Wrong ints", ints, err)
+	}
+	ints, err = config.GetIntSlice("nums")
+	if nil != err || 2 != len(ints) || 2 != ints[1] {
+		t.Error("Wrong ints", ints, err)
+	}
+	durations, err := config.GetDurationSlice("timeouts")
+	if nil != err || 2 != len(durations) || 5*time.Minute != durations[1] {
+		t.Error("Wrong durations", durations, err)
+	}
+	durations, err = config.GetDurationSlice("delays")
+	if nil != err || 2 != len(durations) || 2*time.Second != durations[1] {
+		t.Error("Wrong durations", durations, err)
+	}
+	defaults, err := config.GetStringSlice("missing", []string{"d1", "d2"})
+	if nil != err || 2 != len(defaults) {
+		t.Error("Wrong defaults", defaults, err)
+	}
+
+	if _, err = config.GetStringSlice("bad"); nil == err {
+		t.Error("Missing quote should fail")
+	}
+	if _, err = config.GetIntSlice("hosts"); nil == err {
+		t.Error("Conversion should fail")
+	}
+	if _, err = config.GetStringSlice("missing"); nil == err {
+		t.Error("Missing key should fail")
+	}
+}
+
+// Check separator and quotes settings.
+func TestList1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetListSeparator(";")
+	builder.SetListQuotes("")
+	config, _ := builder.LoadTxt(strings.NewReader(`hosts = "a, b"; c`))
+	values, err := config.GetStringSlice("hosts")
+	if nil != err || 2 != len(values) || `"a, b"` != values[0] || "c" != values[1] {
+		t.Error("Wrong values", values, err)
+	}
+
+	var result struct {
+		Hosts []string `config:"hosts"`
+	}
+	if err = config.Unmarshal("", &result); nil != err || 2 != len(result.Hosts) {
+		t.Error("Unmarshal Failed", result, err)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
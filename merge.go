@@ -0,0 +1,298 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MergePolicy define how values of a new source are merged with existing ones.
+type MergePolicy int
+
+const (
+	// FirstWins existing values are kept, first loaded source wins (default).
+	FirstWins MergePolicy = iota
+	// LastWins new values override existing ones, last loaded source wins.
+	LastWins
+)
+
+// String Stringer interface implementation
+func (p MergePolicy) String() string {
+	switch p {
+	case FirstWins:
+		return "FirstWins"
+	case LastWins:
+		return "LastWins"
+	}
+	return "MergePolicy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// unsetValue txt value removing a key.
+const unsetValue = "!unset"
+
+// unsetMarker is stored in place of a key removed by a source (json null, txt '!unset').
+// It is merged as any other value, and hides the key, and any default for it.
+type unsetMarker struct{}
+
+// String Stringer interface implementation
+func (u unsetMarker) String() string {
+	return unsetValue
+}
+
+// unset marker of removed keys
+var unset = unsetMarker{}
+
+// isUnset check if a value is the unset marker.
+func isUnset(value interface{}) bool {
+	_, ok := value.(unsetMarker)
+	return ok
+}
+
+// stripUnset return value without removed entries, nested maps are copied.
+func stripUnset(value interface{}) interface{} {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if !isUnset(v) {
+			result[k] = stripUnset(v)
+		}
+	}
+	return result
+}
+
+// markUnset replace nil values (json or yaml null) of nested maps with unset marker.
+func markUnset(values map[string]interface{}) {
+	for k, v := range values {
+		switch tv := v.(type) {
+		case nil:
+			values[k] = unset
+		case map[string]interface{}:
+			markUnset(tv)
+		}
+	}
+}
+
+// ArrayStrategy define how two slices found for the same key are merged.
+type ArrayStrategy int
+
+const (
+	// ArrayPolicy slices follow the MergePolicy : keep first or replace (default).
+	ArrayPolicy ArrayStrategy = iota
+	// ArrayKeepFirst existing slice is kept.
+	ArrayKeepFirst
+	// ArrayReplace new slice replace existing one.
+	ArrayReplace
+	// ArrayAppend new items are added after existing ones.
+	ArrayAppend
+	// ArrayPrepend new items are added before existing ones.
+	ArrayPrepend
+	// ArrayUnion new items are added after existing ones, duplicates are removed.
+	ArrayUnion
+	// ArrayMergeByKey items are maps merged when they share the same value for a field (i.e. "name").
+	ArrayMergeByKey
+)
+
+// String Stringer interface implementation
+func (a ArrayStrategy) String() string {
+	switch a {
+	case ArrayPolicy:
+		return "ArrayPolicy"
+	case ArrayKeepFirst:
+		return "ArrayKeepFirst"
+	case ArrayReplace:
+		return "ArrayReplace"
+	case ArrayAppend:
+		return "ArrayAppend"
+	case ArrayPrepend:
+		return "ArrayPrepend"
+	case ArrayUnion:
+		return "ArrayUnion"
+	case ArrayMergeByKey:
+		return "ArrayMergeByKey"
+	}
+	return "ArrayStrategy(" + strconv.Itoa(int(a)) + ")"
+}
+
+// arrayRule strategy, and field used by ArrayMergeByKey.
+type arrayRule struct {
+	strategy ArrayStrategy
+	field    string
+}
+
+// merger merge maps according to a policy and array rules.
+type merger struct {
+	policy  MergePolicy
+	array   arrayRule            // default rule
+	arrays  map[string]arrayRule // rules by key path (i.e. "servers", "http.allowed_hosts")
+	source  Source               // source of merged values
+	from    map[string]Source    // source of merged values by key, may be nil
+	origins map[string]Source    // where to record source of values set, may be nil
+}
+
+// mergeMap merge two maps. Copy all entries from the first to the second.
+// Existing entries of the second map are overridden only when policy is LastWins,
+// nested maps are always merged recursively.
+func mergeMap(src, dest map[string]interface{}, policy MergePolicy) {
+	m := &merger{policy: policy}
+	m.mergeMap(src, dest, "")
+}
+
+// mergeMap merge src into dest, prefix is the key path of both maps.
+func (m *merger) mergeMap(src, dest map[string]interface{}, prefix string) {
+	// iterate over all key, values from src
+	for k, v := range src {
+		key := k
+		if "" != prefix {
+			key = prefix + "." + k
+		}
+		// search in dest value for same key
+		v2, found := dest[k]
+		if !found {
+			// not found : set it
+			dest[k] = v
+			m.record(key, v)
+			continue
+		}
+		// if v AND v2 are map[string]interface merge recursively
+		if tsrc, ok := v.(map[string]interface{}); ok {
+			if tdest, ok := v2.(map[string]interface{}); ok {
+				m.mergeMap(tsrc, tdest, key)
+				continue
+			}
+		}
+		// if v AND v2 are slices apply array rule
+		if tsrc, ok := v.([]interface{}); ok {
+			if tdest, ok := v2.([]interface{}); ok {
+				dest[k] = m.mergeSlice(tsrc, tdest, key)
+				continue
+			}
+		}
+		if LastWins == m.policy {
+			dest[k] = v
+			if nil != m.origins {
+				forget(m.origins, key)
+			}
+			m.record(key, v)
+		}
+	}
+}
+
+// record source of value set for key, and of all its nested values.
+func (m *merger) record(key string, value interface{}) {
+	if nil == m.origins {
+		return
+	}
+	if entries, ok := value.(map[string]interface{}); ok {
+		for k, v := range entries {
+			m.record(key+"."+k, v)
+		}
+		return
+	}
+	if isUnset(value) {
+		// removed values have no source
+		return
+	}
+	if source, ok := m.from[key]; ok {
+		m.origins[key] = source
+	} else {
+		m.origins[key] = m.source
+	}
+}
+
+// forget remove sources of key and of its nested values, replaced by a new value.
+func forget(origins map[string]Source, key string) {
+	delete(origins, key)
+	prefix := key + "."
+	for k := range origins {
+		if strings.HasPrefix(k, prefix) {
+			delete(origins, k)
+		}
+	}
+}
+
+// mergeSlice merge two slices found for key, return the merged slice.
+func (m *merger) mergeSlice(src, dest []interface{}, key string) []interface{} {
+	rule, found := m.arrays[key]
+	if !found {
+		rule = m.array
+	}
+	switch rule.strategy {
+	case ArrayKeepFirst:
+		return dest
+	case ArrayPolicy:
+		// Follow merge policy
+		if LastWins != m.policy {
+			return dest
+		}
+	}
+	result := m.arrayMerge(rule, src, dest, key)
+	m.record(key, result)
+	return result
+}
+
+// arrayMerge apply rule to merge slices found for key.
+func (m *merger) arrayMerge(rule arrayRule, src, dest []interface{}, key string) []interface{} {
+	switch rule.strategy {
+	case ArrayAppend:
+		return append(append([]interface{}{}, dest...), src...)
+	case ArrayPrepend:
+		return append(append([]interface{}{}, src...), dest...)
+	case ArrayUnion:
+		var result []interface{}
+		for _, item := range append(append([]interface{}{}, dest...), src...) {
+			if indexOf(result, item) < 0 {
+				result = append(result, item)
+			}
+		}
+		return result
+	case ArrayMergeByKey:
+		result := append([]interface{}{}, dest...)
+		for _, item := range src {
+			if tsrc, ok := item.(map[string]interface{}); ok {
+				if id, ok := tsrc[rule.field]; ok {
+					if pos := indexByField(result, rule.field, id); pos >= 0 {
+						// items are nested values of key, at their index
+						m.mergeMap(tsrc, result[pos].(map[string]interface{}), key+"."+strconv.Itoa(pos))
+						continue
+					}
+				}
+			}
+			result = append(result, item)
+		}
+		return result
+	}
+	// ArrayReplace, or ArrayPolicy with LastWins
+	return src
+}
+
+// indexOf return position of value in items, or -1
+func indexOf(items []interface{}, value interface{}) int {
+	for i, item := range items {
+		if reflect.DeepEqual(item, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexByField return position of the first map having field set to value, or -1
+func indexByField(items []interface{}, field string, value interface{}) int {
+	for i, item := range items {
+		if entry, ok := item.(map[string]interface{}); ok {
+			if id, ok := entry[field]; ok && reflect.DeepEqual(id, value) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,301 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check mergeMap with both policies.
+func TestMerge0(t *testing.T) {
+	src := map[string]interface{}{"key": "new", "added": "yes", "sub": map[string]interface{}{"key": "new"}, "scalar": map[string]interface{}{}}
+	dest := map[string]interface{}{"key": "old", "sub": map[string]interface{}{"key": "old", "other": "old"}, "scalar": "old"}
+
+	mergeMap(src, dest, FirstWins)
+	if "old" != dest["key"] || "yes" != dest["added"] || "old" != dest["scalar"] {
+		t.Error("Wrong merge result :", dest)
+	}
+	if sub := dest["sub"].(map[string]interface{}); "old" != sub["key"] {
+		t.Error("Wrong merge result :", sub)
+	}
+
+	mergeMap(src, dest, LastWins)
+	if "new" != dest["key"] || "yes" != dest["added"] {
+		t.Error("Wrong merge result :", dest)
+	}
+	if _, ok := dest["scalar"].(map[string]interface{}); !ok {
+		t.Error("Wrong merge result :", dest)
+	}
+	// nested maps are merged, not replaced
+	if sub := dest["sub"].(map[string]interface{}); "new" != sub["key"] || "old" != sub["other"] {
+		t.Error("Wrong merge result :", sub)
+	}
+
+	if "FirstWins" != FirstWins.String() || "LastWins" != LastWins.String() || "MergePolicy(7)" != MergePolicy(7).String() {
+		t.Error("Wrong String() value")
+	}
+}
+
+// Check LastWins policy with json and txt.
+func TestMerge1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	if FirstWins != builder.MergePolicy() {
+		t.Error("Wrong default policy", builder.MergePolicy())
+	}
+	builder.SetMergePolicy(LastWins)
+	str := "{ \"nope\": true, \"key\":\"value\",  \"sub\": { \"bool\": false, \"keep\": 1 }}"
+	str2 := "nope = false\nsub.bool = true\nkey = first\nkey = second\n"
+	_, err := builder.LoadJSON(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+	config, err := builder.LoadTxt(strings.NewReader(str2))
+	if nil != err {
+		t.Error("LoadTxt Failed", err)
+	}
+
+	expected := map[string]string{
+		"nope":     "false",
+		"sub.bool": "true",
+		"sub.keep": "1",
+		"key":      "second",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+}
+
+// Check LastWins policy with files and per call policy.
+func TestMerge2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	_, err := builder.LoadFiles("testdata/config00.json")
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+
+	// base first, then overlays
+	config, err := builder.WithMergePolicy(LastWins).LoadFiles("testdata/conf.d/00-base.json", "testdata/conf.d/10-site.yaml")
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	if FirstWins != builder.MergePolicy() {
+		t.Error("Builder policy should not change", builder.MergePolicy())
+	}
+	port, serr := config.GetInt("database.port")
+	if nil != serr || 5432 != port {
+		t.Error("Wrong value found :", port, serr)
+	}
+	str, serr := config.GetString("database.user")
+	if nil != serr || "john" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+
+	// Back to first wins
+	config, err = builder.LoadFiles("testdata/conf.d/20-local.conf")
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr = config.GetString("name")
+	if nil != serr || "base" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// Check array strategies.
+func TestMerge3(t *testing.T) {
+	base := "{ \"hosts\": [\"a\", \"b\"], \"servers\": [ {\"name\": \"alpha\", \"port\": 1}, {\"name\": \"beta\", \"port\": 2} ] }"
+	overlay := "{ \"hosts\": [\"b\", \"c\"], \"servers\": [ {\"name\": \"beta\", \"port\": 3, \"tls\": true}, {\"name\": \"gamma\"}, \"raw\" ] }"
+
+	expected := map[ArrayStrategy]string{
+		ArrayPolicy:    "[a b]",
+		ArrayKeepFirst: "[a b]",
+		ArrayReplace:   "[b c]",
+		ArrayAppend:    "[a b b c]",
+		ArrayPrepend:   "[b c a b]",
+		ArrayUnion:     "[a b c]",
+	}
+	for strategy, value := range expected {
+		builder := NewBuilder("Ctx_", nil)
+		builder.SetArrayStrategy(strategy)
+		if strategy != builder.ArrayStrategy() {
+			t.Error("Wrong strategy", builder.ArrayStrategy())
+		}
+		builder.LoadJSON(strings.NewReader(base))
+		config, err := builder.LoadJSON(strings.NewReader(overlay))
+		if nil != err {
+			t.Error("LoadJSON Failed", err)
+		}
+		str, serr := config.GetString("hosts")
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", strategy, ":", str, serr)
+		}
+	}
+
+	// Policy is used by default
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMergePolicy(LastWins)
+	builder.LoadJSON(strings.NewReader(base))
+	config, _ := builder.LoadJSON(strings.NewReader(overlay))
+	str, serr := config.GetString("hosts")
+	if nil != serr || "[b c]" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// Check per key array strategies and merge by key.
+func TestMerge4(t *testing.T) {
+	base := "{ \"hosts\": [\"a\", \"b\"], \"sub\": { \"hosts\": [\"a\"] }, \"servers\": [ {\"name\": \"alpha\", \"port\": 1}, {\"name\": \"beta\", \"port\": 2} ] }"
+	overlay := "{ \"hosts\": [\"b\", \"c\"], \"sub\": { \"hosts\": [\"d\"] }, \"servers\": [ {\"name\": \"beta\", \"port\": 3, \"tls\": true}, {\"name\": \"gamma\"}, \"raw\" ] }"
+
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMergePolicy(LastWins)
+	builder.SetArrayStrategy(ArrayKeepFirst)
+	builder.SetKeyArrayStrategy("sub.hosts", ArrayAppend)
+	builder.SetKeyArrayStrategy("servers", ArrayMergeByKey, "name")
+	builder.LoadJSON(strings.NewReader(base))
+	config, err := builder.LoadJSON(strings.NewReader(overlay))
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+
+	expected := map[string]string{
+		"hosts":     "[a b]",
+		"sub.hosts": "[a d]",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+
+	raw, _, _ := config.(*ConfigImpl).get("servers")
+	servers := raw.([]interface{})
+	if 4 != len(servers) {
+		t.Error("Wrong value found :", servers)
+	}
+	beta := servers[1].(map[string]interface{})
+	if 3.0 != beta["port"] || true != beta["tls"] {
+		t.Error("Wrong value found :", beta)
+	}
+	if gamma := servers[2].(map[string]interface{}); "gamma" != gamma["name"] {
+		t.Error("Wrong value found :", gamma)
+	}
+	if "raw" != servers[3] {
+		t.Error("Wrong value found :", servers[3])
+	}
+	// merged items are recorded at their index
+	origins := config.(*ConfigImpl).def.origins
+	for _, key := range []string{"servers.1.port", "servers.1.tls"} {
+		if _, found := origins[key]; !found {
+			t.Error("Missing origin of", key)
+		}
+	}
+	for _, key := range []string{"servers.name", "servers.port", "servers.tls"} {
+		if _, found := origins[key]; found {
+			t.Error("Unexpected origin of", key)
+		}
+	}
+
+	if "ArrayMergeByKey" != ArrayMergeByKey.String() || "ArrayStrategy(42)" != ArrayStrategy(42).String() {
+		t.Error("Wrong String() value")
+	}
+}
+
+// Check unset markers with first wins policy.
+func TestMerge5(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.AddDefault("debug", "true")
+	builder.AddDefault("database.url", "default url")
+	// most specific first
+	overlay := "{ \"key\": null, \"debug\": null, \"database\": null, \"sub\": { \"other\": null } }"
+	base := "{ \"key\": \"value\", \"database\": { \"url\": \"base url\" }, \"sub\": { \"other\": 1, \"kept\": 2 }, \"ref\": \"${key}\" }"
+	builder.LoadJSON(strings.NewReader(overlay))
+	config, err := builder.LoadJSON(strings.NewReader(base))
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+
+	for _, key := range []string{"key", "debug", "database.url", "sub.other"} {
+		_, serr := config.GetString(key)
+		if _, ok := serr.(*MissingKeyError); !ok {
+			t.Error("Key '"+key+"' should be missing", serr)
+		}
+	}
+	// inline default value is still used
+	str, serr := config.GetString("database.url", "deflt")
+	if nil != serr || "deflt" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	str, serr = config.GetString("sub.kept")
+	if nil != serr || "2" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	if _, serr = config.GetConfig("database"); nil == serr {
+		t.Error("Key 'database' should be missing")
+	}
+	if _, serr = config.GetString("ref"); nil == serr {
+		t.Error("Expand of removed key should fail")
+	}
+}
+
+// Check unset markers with last wins policy.
+func TestMerge6(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMergePolicy(LastWins)
+	builder.LoadJSON(strings.NewReader("{ \"key\": \"value\", \"other\": \"value\", \"sub\": { \"key\": 1 } }"))
+	config, err := builder.LoadTxt(strings.NewReader("key = !unset\nsub.key = !unset\n"))
+	if nil != err {
+		t.Error("LoadTxt Failed", err)
+	}
+	for _, key := range []string{"key", "sub.key"} {
+		if _, serr := config.GetString(key); nil == serr {
+			t.Error("Key '" + key + "' should be missing")
+		}
+	}
+	str, serr := config.GetString("other")
+	if nil != serr || "value" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+
+	// a later source may set it again
+	config, err = builder.LoadYAML(strings.NewReader("key: again\nother: ~\n"))
+	if nil != err {
+		t.Error("LoadYAML Failed", err)
+	}
+	str, serr = config.GetString("key")
+	if nil != serr || "again" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	if _, serr := config.GetString("other"); nil == serr {
+		t.Error("Key 'other' should be missing")
+	}
+}
+
+// Check removed keys are not part of sections.
+func TestMerge7(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, _ := builder.LoadJSON(strings.NewReader(`{ "sub": { "a": null, "b": "x", "c": { "d": null } }, "ref": "${sub}" }`))
+	for _, key := range []string{"sub", "ref"} {
+		str, serr := config.GetString(key)
+		if nil != serr || "map[b:x c:map[]]" != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,106 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"path"
+	"strings"
+)
+
+// profilesKey section holding values of each profile within a source,
+// i.e. 'profiles.dev.database.url'.
+const profilesKey = "profiles"
+
+// SetActiveProfiles define active profiles, least specific first (i.e. "dev", "local").
+// When loading app.json, overlays app.dev.json then app.local.json are also loaded,
+// and 'profiles.dev' then 'profiles.local' sections of each source override its values.
+func (b *ConfigBuilder) SetActiveProfiles(profiles ...string) {
+	b.conf.def.profiles = append([]string{}, profiles...)
+}
+
+// ActiveProfiles return active profiles
+func (b *ConfigBuilder) ActiveProfiles() []string {
+	return b.conf.Profiles()
+}
+
+// profileFiles return filename and its profile overlays, in load order.
+// With FirstWins policy most specific overlay is loaded first, base file last.
+func (b *ConfigBuilder) profileFiles(filename string) []string {
+	profiles := b.conf.def.profiles
+	if 0 == len(profiles) {
+		return []string{filename}
+	}
+	ext := path.Ext(filename)
+	stem := filename[:len(filename)-len(ext)]
+	result := []string{filename}
+	for _, profile := range profiles {
+		result = append(result, stem+"."+profile+ext)
+	}
+	if LastWins != b.mergePolicy {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	return result
+}
+
+// dropProfileOverlays remove profile overlays (app.dev.json) of files (app.json) within filenames,
+// once profiles are set. Overlays are loaded by loadFiles, according to active profiles.
+// Without profiles all files are kept (db.replica.conf is not an overlay of db.conf).
+func (b *ConfigBuilder) dropProfileOverlays(filenames []string) []string {
+	if 0 == len(b.conf.def.profiles) {
+		return filenames
+	}
+	names := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		names[filename] = true
+	}
+	var result []string
+	for _, filename := range filenames {
+		ext := path.Ext(filename)
+		stem := filename[:len(filename)-len(ext)]
+		if dot := strings.LastIndex(stem, "."); dot > strings.LastIndexAny(stem, "/\\") {
+			if names[stem[:dot]+ext] {
+				continue
+			}
+		}
+		result = append(result, filename)
+	}
+	return result
+}
+
+// applyProfiles override loaded values with sections of active profiles, and remove the profiles section.
+// Sources of overlay values are moved to their new keys.
+// Without active profiles a 'profiles' key is a regular value.
+func (b *ConfigBuilder) applyProfiles(loaded *sourceValues) {
+	if 0 == len(b.conf.def.profiles) {
+		return
+	}
+	section, ok := loaded.values[profilesKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(loaded.values, profilesKey)
+	for _, profile := range b.conf.def.profiles {
+		overlay, ok := section[profile].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		prefix := profilesKey + "." + profile + "."
+		from := make(map[string]Source)
+		for key, source := range loaded.origins {
+			if strings.HasPrefix(key, prefix) {
+				from[key[len(prefix):]] = source
+			}
+		}
+		m := &merger{policy: LastWins, array: b.arrayRule, arrays: b.arrayRules,
+			source: loaded.source, from: from, origins: loaded.origins}
+		m.mergeMap(overlay, loaded.values, "")
+	}
+	forget(loaded.origins, profilesKey)
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
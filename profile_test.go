@@ -0,0 +1,122 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkProfiles check values loaded from testdata/profiles with dev and local profiles.
+func checkProfiles(t *testing.T, config GoConfig) {
+	expected := map[string]string{
+		"name":          "app",
+		"database.host": "dev.example.com",
+		"database.port": "5432",
+		"dev":           "true",
+		"log":           "debug",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+	// inactive profiles are ignored
+	for _, key := range []string{"prod", "profiles"} {
+		if _, serr := config.GetString(key); nil == serr {
+			t.Error("Key '" + key + "' should be missing")
+		}
+	}
+}
+
+// Check profile overlays with both merge policies.
+func TestProfile0(t *testing.T) {
+	for _, policy := range []MergePolicy{FirstWins, LastWins} {
+		builder := NewBuilder("Ctx_", nil)
+		builder.SetMergePolicy(policy)
+		builder.SetActiveProfiles("dev", "local")
+		config, err := builder.LoadFiles("testdata/profiles/app.json")
+		if nil != err {
+			t.Error("LoadFiles Failed", err)
+		}
+		checkProfiles(t, config)
+
+		profiles := config.Profiles()
+		if 2 != len(profiles) || "dev" != profiles[0] || "local" != profiles[1] {
+			t.Error("Wrong profiles", profiles)
+		}
+		sub, _ := config.GetConfig("database")
+		if 2 != len(sub.Profiles()) {
+			t.Error("Wrong profiles", sub.Profiles())
+		}
+	}
+}
+
+// Check profile overlays with LoadDir, and without profiles.
+func TestProfile1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetActiveProfiles("dev", "local")
+	config, err := builder.LoadDir("testdata/profiles")
+	if nil != err {
+		t.Error("LoadDir Failed", err)
+	}
+	checkProfiles(t, config)
+
+	builder = NewBuilder("Ctx_", nil)
+	if 0 != len(builder.ActiveProfiles()) {
+		t.Error("Wrong profiles", builder.ActiveProfiles())
+	}
+	builder.SetMergePolicy(LastWins)
+	config, err = builder.LoadDir("testdata/profiles")
+	if nil != err {
+		t.Error("LoadDir Failed", err)
+	}
+	// without profiles, every file is a regular one, loaded in lexical order
+	expected := map[string]string{
+		"database.host": "prod.example.com",
+		"database.port": "5432",
+		"log":           "info",
+		"dev":           "true",
+		"prod":          "true",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+}
+
+// Check profiles sections in txt.
+func TestProfile2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetActiveProfiles("prod")
+	str := "url = localhost\nprofiles.prod.url = prod.example.com\nprofiles.dev.url = dev.example.com\n"
+	config, err := builder.LoadTxt(strings.NewReader(str))
+	if nil != err {
+		t.Error("LoadTxt Failed", err)
+	}
+	str, serr := config.GetString("url")
+	if nil != serr || "prod.example.com" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// Check profiles section is a regular value without active profiles.
+func TestProfile3(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, _ := builder.LoadJSON(strings.NewReader(`{ "profiles": { "a": 1 } }`))
+	str, serr := config.GetString("profiles.a")
+	if nil != serr || "1" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
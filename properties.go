@@ -0,0 +1,155 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// LoadProperties Load a map from a java .properties Stream
+// merge loaded value with previous one.
+// Keys are nested on '.' as in txt files. Supported syntax :
+// '#' and '!' comments, '=', ':' or white space separators,
+// line continuation with a trailing '\', and escapes (\uXXXX, \t, \=, ...).
+func (b *ConfigBuilder) LoadProperties(r io.Reader) (GoConfig, error) {
+	return b.loadProperties(r, "")
+}
+
+// loadProperties Load a map from a java .properties Stream read from filename (may be empty).
+func (b *ConfigBuilder) loadProperties(r io.Reader, filename string) (GoConfig, error) {
+
+	scanner := bufio.NewScanner(r)
+	lineNb := 0
+	loaded := newSourceValues(SourceProperties, filename)
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+		// ignore empty lines and comments
+		if "" == line || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		// Join continuation lines
+		start := lineNb
+		for continued(line) {
+			line = line[:len(line)-1]
+			if !scanner.Scan() {
+				break
+			}
+			lineNb++
+			line += strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+		}
+		key, value, err := splitProperty(line)
+		if nil != err {
+			return b.conf, &ParseError{file: filename, line: start, msg: err.Error() + " : '" + line + "'"}
+		}
+		// Set Value in a spare config
+		loaded.set(key, value, filename, start, b.mergePolicy)
+	}
+	if err := scanner.Err(); nil != err {
+		return b.conf, err
+	}
+	// Merge new config and current one.
+	b.merge(loaded)
+
+	return b.conf, nil
+}
+
+// LoadPropertiesFile load from a file
+func (b *ConfigBuilder) LoadPropertiesFile(filename string) (GoConfig, error) {
+	f, err := os.Open(filename)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	return b.loadProperties(r, filename)
+}
+
+// continued check if a line ends with an odd number of '\'
+func continued(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && '\\' == line[i]; i-- {
+		count++
+	}
+	return 1 == count%2
+}
+
+// splitProperty split a logical line into an unescaped key and value.
+func splitProperty(line string) (string, string, error) {
+	// Key ends at first unescaped '=', ':' or white space
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if '\\' == line[i] {
+			i++
+		} else if '=' == line[i] || ':' == line[i] || unicode.IsSpace(rune(line[i])) {
+			end = i
+			break
+		}
+	}
+	rawKey := line[:end]
+	rawValue := strings.TrimLeftFunc(line[end:], unicode.IsSpace)
+	if strings.HasPrefix(rawValue, "=") || strings.HasPrefix(rawValue, ":") {
+		rawValue = strings.TrimLeftFunc(rawValue[1:], unicode.IsSpace)
+	}
+	key, err := unescapeProperty(rawKey)
+	if nil != err {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rawValue)
+	if nil != err {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// unescapeProperty resolve escape sequences of .properties files.
+func unescapeProperty(str string) (string, error) {
+	if !strings.Contains(str, "\\") {
+		return str, nil
+	}
+	var buffer bytes.Buffer
+	for i := 0; i < len(str); i++ {
+		if '\\' != str[i] {
+			buffer.WriteByte(str[i])
+			continue
+		}
+		i++
+		if i >= len(str) {
+			break
+		}
+		switch str[i] {
+		case 't':
+			buffer.WriteByte('\t')
+		case 'n':
+			buffer.WriteByte('\n')
+		case 'r':
+			buffer.WriteByte('\r')
+		case 'f':
+			buffer.WriteByte('\f')
+		case 'u':
+			if i+5 > len(str) {
+				return "", &strconv.NumError{Func: "unescape", Num: str[i-1:], Err: strconv.ErrSyntax}
+			}
+			code, err := strconv.ParseUint(str[i+1:i+5], 16, 16)
+			if nil != err {
+				return "", &strconv.NumError{Func: "unescape", Num: str[i-1 : i+5], Err: strconv.ErrSyntax}
+			}
+			buffer.WriteRune(rune(code))
+			i += 4
+		default:
+			buffer.WriteByte(str[i])
+		}
+	}
+	return buffer.String(), nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
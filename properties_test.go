@@ -0,0 +1,94 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check properties parsing.
+func TestProperties0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "# comment\n! other comment\nkey=value\nsub.colon:other\nsub.space  spaced value\nmulti = one, \\\n    two\n" +
+		"escaped\\=key = v\\u00e9\\tx\nempty\nslash = c:\\\\\n"
+	config, err := builder.LoadProperties(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadProperties Failed", err)
+	}
+
+	expected := map[string]string{
+		"key":         "value",
+		"sub.colon":   "other",
+		"sub.space":   "spaced value",
+		"multi":       "one, two",
+		"escaped=key": "v\u00e9\tx",
+		"empty":       "",
+		"slash":       "c:\\",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+}
+
+// Check properties parsing with invalid escape.
+func TestProperties1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	_, err := builder.LoadProperties(strings.NewReader("key=value\n\nbad = \\u12\n"))
+
+	if nil == err {
+		t.Error("LoadProperties should Failed")
+	}
+	if perr, ok := err.(*ParseError); !ok || 3 != perr.line {
+		t.Error("Wrong error :", err)
+	}
+
+	_, err = builder.LoadPropertiesFile("testdata/invalid/config.properties")
+	if perr, ok := err.(*ParseError); !ok || 3 != perr.line || "testdata/invalid/config.properties" != perr.file {
+		t.Error("Wrong error :", err)
+	}
+}
+
+// Check properties files.
+func TestProperties2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetMaxRecursion(5)
+	os.Setenv("CTX_ENV", "dev")
+	config, err := builder.LoadFiles("testdata/config00.properties", "testdata/config00.txt")
+
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr := config.GetString("database.pwd")
+	if nil != serr {
+		t.Error("Key 'database.pwd' not found", serr)
+	}
+	if "development" != str {
+		t.Error("Wrong value found :", str)
+	}
+	str, serr = config.GetString("greeting")
+	if nil != serr {
+		t.Error("Key 'greeting' not found", serr)
+	}
+	if "Hello, World" != str {
+		t.Error("Wrong value found :", str)
+	}
+
+	_, err = builder.LoadPropertiesFile("missing.properties")
+	if nil == err {
+		t.Error("Load missing file should Fail")
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
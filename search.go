@@ -0,0 +1,60 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// searchExtensions extensions of files looked for by LoadFromSearchPath, by precedence.
+var searchExtensions = []string{".json", ".yaml", ".yml", ".toml", ".ini", ".properties", ".conf"}
+
+// SearchPath return default directories searched for config files of an application,
+// most specific first : working directory, $XDG_CONFIG_HOME/app, ~/.config/app, /etc/app.
+func SearchPath(app string) []string {
+	result := []string{"."}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); "" != xdg {
+		result = append(result, filepath.Join(xdg, app))
+	}
+	if home, err := os.UserHomeDir(); nil == err {
+		dir := filepath.Join(home, ".config", app)
+		if dir != result[len(result)-1] {
+			result = append(result, dir)
+		}
+	}
+	return append(result, filepath.Join("/etc", app))
+}
+
+// LoadFromSearchPath load files named name.json, name.yaml, name.conf ... found in paths,
+// most specific directory first. When paths is empty, SearchPath(name) is used.
+// All files found are loaded, files of most specific directories override others
+// whatever the merge policy. File type is guessed from extension as in LoadFiles.
+// Return loaded files, in load order.
+func (b *ConfigBuilder) LoadFromSearchPath(name string, paths ...string) (GoConfig, []string, error) {
+	if 0 == len(paths) {
+		paths = SearchPath(name)
+	}
+	var filenames []string
+	for _, dir := range paths {
+		for _, ext := range searchExtensions {
+			filename := filepath.Join(dir, name+ext)
+			if info, err := os.Stat(filename); nil == err && !info.IsDir() {
+				filenames = append(filenames, filename)
+			}
+		}
+	}
+	// filenames are sorted by precedence, load most specific last with LastWins policy
+	if LastWins == b.mergePolicy {
+		for i, j := 0, len(filenames)-1; i < j; i, j = i+1, j-1 {
+			filenames[i], filenames[j] = filenames[j], filenames[i]
+		}
+	}
+	conf, err := b.LoadFiles(filenames...)
+	return conf, filenames, err
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
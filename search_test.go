@@ -0,0 +1,74 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Check LoadFromSearchPath with both merge policies.
+func TestSearch0(t *testing.T) {
+	paths := []string{"testdata/search/missing", "testdata/search/local", "testdata/search/empty", "testdata/search/site"}
+	expected := map[string]string{
+		"name":          "local",
+		"database.port": "5433",
+		"database.host": "db.example.com",
+		"log.level":     "debug",
+	}
+	for _, policy := range []MergePolicy{FirstWins, LastWins} {
+		builder := NewBuilder("Ctx_", nil)
+		builder.SetMergePolicy(policy)
+		config, files, err := builder.LoadFromSearchPath("app", paths...)
+		if nil != err {
+			t.Error("LoadFromSearchPath Failed", policy, err)
+		}
+		for key, value := range expected {
+			str, serr := config.GetString(key)
+			if nil != serr || value != str {
+				t.Error("Wrong value found for", key, policy, ":", str, serr)
+			}
+		}
+		if 3 != len(files) {
+			t.Error("Wrong files found", policy, files)
+			continue
+		}
+		first, last := filepath.Join("testdata/search/local", "app.json"), filepath.Join("testdata/search/site", "app.yaml")
+		if LastWins == policy {
+			first, last = last, first
+		}
+		if first != files[0] || last != files[2] {
+			t.Error("Wrong load order", policy, files)
+		}
+	}
+}
+
+// Check default search path.
+func TestSearch1(t *testing.T) {
+	old, set := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	defer func() {
+		if set {
+			os.Setenv("XDG_CONFIG_HOME", old)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	}()
+
+	paths := SearchPath("app")
+	if "." != paths[0] || filepath.Join("/tmp/xdg", "app") != paths[1] || filepath.Join("/etc", "app") != paths[len(paths)-1] {
+		t.Error("Wrong search path", paths)
+	}
+
+	builder := NewBuilder("Ctx_", nil)
+	_, files, err := builder.LoadFromSearchPath("goconfig-search-test-missing")
+	if nil != err || 0 != len(files) {
+		t.Error("No file expected", files, err)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,89 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envFileSuffix suffix of env vars holding the name of a file (CTX_DB_PASSWORD_FILE).
+const envFileSuffix = "_FILE"
+
+// SetEnvFiles should <NAME>_FILE env vars be resolved or not.
+// When enabled, and CTX_DB_PASSWORD is not set, the value of key 'db.password'
+// is read from the file named by CTX_DB_PASSWORD_FILE (i.e. /run/secrets/db_password).
+// keys restrict resolution to the given keys (i.e. "db.password"), other *_FILE vars are regular vars.
+// Without keys, every prefixed *_FILE var is resolved : CTX_LOG_FILE=/var/log/app.log
+// would be read as the secret value of key 'log', not as the value of key 'log.file'.
+// A file that can not be read is reported as an error by getters and LoadEnv.
+func (b *ConfigBuilder) SetEnvFiles(value bool, keys ...string) {
+	b.conf.def.envFiles = value
+	b.conf.def.envFileKeys = nil
+	for _, key := range keys {
+		if nil == b.conf.def.envFileKeys {
+			b.conf.def.envFileKeys = make(map[string]bool)
+		}
+		b.conf.def.envFileKeys[normalizeKey(key)] = true
+	}
+}
+
+// isEnvFileKey check if a <NAME>_FILE env var is resolved for key.
+func (c *ConfigDefault) isEnvFileKey(key string) bool {
+	return c.envFiles && (nil == c.envFileKeys || c.envFileKeys[normalizeKey(key)])
+}
+
+// EnvFiles check if <NAME>_FILE env vars are resolved or not
+func (b *ConfigBuilder) EnvFiles() bool {
+	return b.conf.def.envFiles
+}
+
+// LoadSecretsDir load each file of a directory (i.e. /run/secrets) as a value,
+// merge loaded value with previous one.
+// File names are mapped to keys as env vars are (db_password => db.password),
+// values are files content, without leading and trailing spaces.
+// Sub directories and hidden files are ignored.
+func (b *ConfigBuilder) LoadSecretsDir(dirname string) (GoConfig, error) {
+	infos, err := ioutil.ReadDir(dirname)
+	if nil != err {
+		if b.ignoreMissingFiles && os.IsNotExist(err) {
+			return b.conf, nil
+		}
+		return nil, err
+	}
+	loaded := newSourceValues(SourceSecret, dirname)
+	for _, info := range infos {
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		filename := filepath.Join(dirname, info.Name())
+		value, err := readSecret(filename)
+		if nil != err {
+			return nil, err
+		}
+		key := normalizeKey(b.conf.def.keyFromEnv(info.Name()))
+		if "" != key {
+			loaded.setFrom(key, value, Source{Kind: SourceSecret, File: filename}, b.mergePolicy)
+		}
+	}
+	// Merge new config and current one.
+	b.merge(loaded)
+
+	return b.conf, nil
+}
+
+// readSecret read a file content, without leading and trailing spaces.
+func readSecret(filename string) (string, error) {
+	content, err := ioutil.ReadFile(filename)
+	if nil != err {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,142 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"testing"
+)
+
+// Check <NAME>_FILE env vars.
+func TestSecret0(t *testing.T) {
+	os.Setenv("SEC_DB_PASSWORD_FILE", "testdata/secrets/db_password")
+	os.Setenv("SEC_API_TOKEN_FILE", "testdata/secrets/nope")
+
+	builder := NewBuilder("Sec_", nil)
+	if builder.EnvFiles() {
+		t.Error("Env files should be disabled by default")
+	}
+	config := builder.Config()
+	if _, serr := config.GetString("db.password"); nil == serr {
+		t.Error("Key 'db.password' should be missing")
+	}
+
+	builder.SetEnvFiles(true)
+	str, serr := config.GetString("db.password")
+	if nil != serr || "s3cr3t" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	source, _ := config.Origin("db.password")
+	if (Source{Kind: SourceSecret, File: "testdata/secrets/db_password", Env: "SEC_DB_PASSWORD_FILE"}) != source {
+		t.Error("Wrong source found :", source)
+	}
+
+	// unreadable files are reported
+	_, serr = config.GetString("api.token", "deflt")
+	if _, ok := serr.(*SecretFileError); !ok {
+		t.Error("SecretFileError expected", serr)
+	}
+	if _, serr = config.Expand("${api.token}"); nil == serr {
+		t.Error("Expand of unreadable secret should fail")
+	}
+
+	// env var wins over file
+	os.Setenv("SEC_DB_PASSWORD", "env")
+	str, serr = config.GetString("db.password")
+	if nil != serr || "env" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+	os.Unsetenv("SEC_DB_PASSWORD")
+
+	// LoadEnv read files too
+	_, err := builder.LoadEnv()
+	if nil == err {
+		t.Error("LoadEnv with missing file should Fail")
+	}
+	os.Unsetenv("SEC_API_TOKEN_FILE")
+	config, err = builder.LoadEnv()
+	if nil != err {
+		t.Error("LoadEnv Failed", err)
+	}
+	sub, serr := config.GetConfig("db")
+	if nil != serr {
+		t.Error("Key 'db' not found", serr)
+	}
+	str, serr = sub.GetString("password")
+	if nil != serr || "s3cr3t" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// Check <NAME>_FILE env vars restricted to some keys.
+func TestSecret2(t *testing.T) {
+	os.Setenv("SEC_DB_PASSWORD_FILE", "testdata/secrets/db_password")
+	os.Setenv("SEC_LOG_FILE", "/var/log/app.log")
+	defer os.Unsetenv("SEC_DB_PASSWORD_FILE")
+	defer os.Unsetenv("SEC_LOG_FILE")
+
+	builder := NewBuilder("Sec_", nil)
+	builder.SetEnvFiles(true, "db.password")
+	config, err := builder.LoadEnv()
+	if nil != err {
+		t.Error("LoadEnv Failed", err)
+	}
+	expected := map[string]string{
+		"db.password": "s3cr3t",
+		"log.file":    "/var/log/app.log",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	source, _ := config.Origin("log.file")
+	if (Source{Kind: SourceEnv, Env: "SEC_LOG_FILE"}) != source {
+		t.Error("Wrong source found :", source)
+	}
+}
+
+// Check LoadSecretsDir.
+func TestSecret1(t *testing.T) {
+	builder := NewBuilder("Sec_", nil)
+	config, err := builder.LoadSecretsDir("testdata/secrets")
+	if nil != err {
+		t.Error("LoadSecretsDir Failed", err)
+	}
+
+	expected := map[string]string{
+		"db.password": "s3cr3t",
+		"api.key":     "api-key",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr || value != str {
+			t.Error("Wrong value found for", key, ":", str, serr)
+		}
+	}
+	for _, key := range []string{"hidden", "sub.ignored"} {
+		if _, serr := config.GetString(key); nil == serr {
+			t.Error("Key '" + key + "' should be missing")
+		}
+	}
+	source, _ := config.Origin("api.key")
+	if (Source{Kind: SourceSecret, File: "testdata/secrets/api.key"}) != source {
+		t.Error("Wrong source found :", source)
+	}
+
+	_, err = builder.LoadSecretsDir("testdata/nope")
+	if nil == err {
+		t.Error("LoadSecretsDir of missing directory should Fail")
+	}
+	builder.SetIgnoreMissingFiles(true)
+	_, err = builder.LoadSecretsDir("testdata/nope")
+	if nil != err {
+		t.Error("LoadSecretsDir of missing directory should be ignored", err)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,124 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of Source
+const (
+	SourceJSON       = "json"
+	SourceYAML       = "yaml"
+	SourceTOML       = "toml"
+	SourceINI        = "ini"
+	SourceProperties = "properties"
+	SourceDotEnv     = "dotenv"
+	SourceTxt        = "txt"
+	// SourceFlag value read from a command line flag.
+	SourceFlag = "flag"
+	// SourceCommandLine value read from a '--set key=value' argument.
+	SourceCommandLine = "command line"
+	// SourceDefault value added with AddDefault or given to NewBuilder.
+	SourceDefault = "default"
+	// SourceEnv value read from an env var.
+	SourceEnv = "env"
+	// SourceSecret value read from a secret file (<NAME>_FILE env var or secrets directory).
+	SourceSecret = "secret"
+	// SourceArgument default value given to a getter.
+	SourceArgument = "argument"
+	// SourceUnknown value stored with SetValue.
+	SourceUnknown = "unknown"
+)
+
+// Source describe where a value comes from.
+type Source struct {
+	// Kind of source, one of SourceXxx constants.
+	Kind string
+	// File name, empty when read from a stream.
+	File string
+	// Line number within File, for txt like formats only.
+	Line int
+	// Env name of the env var, for SourceEnv only.
+	Env string
+}
+
+// String Stringer interface implementation
+func (s Source) String() string {
+	switch {
+	case "" != s.Env:
+		return fmt.Sprintf("%s '%s'", s.Kind, s.Env)
+	case "" != s.File && s.Line > 0:
+		return fmt.Sprintf("%s file '%s' line %d", s.Kind, s.File, s.Line)
+	case "" != s.File:
+		return fmt.Sprintf("%s file '%s'", s.Kind, s.File)
+	case s.Line > 0:
+		return fmt.Sprintf("%s line %d", s.Kind, s.Line)
+	}
+	return s.Kind
+}
+
+// sourceValues values read from a source, before being merged into config.
+type sourceValues struct {
+	source  Source                 // source of all values
+	values  map[string]interface{} // nested values
+	origins map[string]Source      // source of each value, by key, for txt like formats
+}
+
+// newSourceValues create an empty set of values read from a source.
+func newSourceValues(kind, filename string) *sourceValues {
+	return &sourceValues{
+		source:  Source{Kind: kind, File: filename},
+		values:  make(map[string]interface{}),
+		origins: make(map[string]Source),
+	}
+}
+
+// set store a value read at line of file, existing value is overridden according to policy.
+func (s *sourceValues) set(key string, value interface{}, file string, line int, policy MergePolicy) {
+	s.setFrom(key, value, Source{Kind: s.source.Kind, File: file, Line: line}, policy)
+}
+
+// setFrom store a value read from source, existing value is overridden according to policy.
+func (s *sourceValues) setFrom(key string, value interface{}, source Source, policy MergePolicy) {
+	conf := &ConfigImpl{values: s.values}
+	if conf.setValue(key, value, policy) {
+		key = storedKey(key)
+		if _, ok := value.(map[string]interface{}); !ok {
+			// value replace any previous one, and its nested values
+			forget(s.origins, key)
+		}
+		if !isUnset(value) {
+			s.origins[key] = source
+		}
+	}
+}
+
+// normalizeKey remove spaces and empty parts of a dotted key,
+// array indexes are dotted parts ('servers[0]' => 'servers.0').
+func normalizeKey(key string) string {
+	return joinKeyParts(splitKey(key))
+}
+
+// storedKey remove spaces and empty parts of a dotted key, as stored in nested maps by sources
+// (brackets are kept as is).
+func storedKey(key string) string {
+	return joinKeyParts(strings.Split(key, "."))
+}
+
+// joinKeyParts join non empty parts of a key, without spaces.
+func joinKeyParts(keys []string) string {
+	var parts []string
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); "" != k {
+			parts = append(parts, k)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,124 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Check origin of values loaded from files.
+func TestSource0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.AddDefault("intl.currency", "EUR")
+	os.Setenv("CTX_INTL_COUNTRY", "FR")
+	config, err := builder.LoadFiles("testdata/config00.json", "testdata/config00.txt", "testdata/include/main.conf")
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+
+	expected := map[string]Source{
+		"database.user":  {Kind: SourceJSON, File: "testdata/config00.json"},
+		"max_proc":       {Kind: SourceTxt, File: "testdata/config00.txt", Line: 9},
+		"dev.db.pwd":     {Kind: SourceTxt, File: "testdata/config00.txt", Line: 6},
+		"db.host":        {Kind: SourceTxt, File: "testdata/include/conf.d/00-first.conf", Line: 3},
+		"intl.currency":  {Kind: SourceDefault},
+		"intl.country":   {Kind: SourceEnv, Env: "CTX_INTL_COUNTRY"},
+		" intl.locale ":  {Kind: SourceJSON, File: "testdata/config00.json"},
+		"database..user": {Kind: SourceJSON, File: "testdata/config00.json"},
+	}
+	for key, value := range expected {
+		source, found := config.Origin(key)
+		if !found {
+			t.Error("Key '" + key + "' not found")
+		}
+		if value != source {
+			t.Error("Wrong source found for", key, ":", source)
+		}
+	}
+
+	source, found := config.Origin("nope", "deflt")
+	if !found || SourceArgument != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+	if _, found = config.Origin("nope"); found {
+		t.Error("Key 'nope' should be missing")
+	}
+
+	// sub config use full keys
+	sub, _ := config.GetConfig("database")
+	source, found = sub.Origin("user")
+	if !found || SourceJSON != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+}
+
+// Check origin with last wins policy and stream parsing.
+func TestSource1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.LoadTxt(strings.NewReader("key = first\nkey = second\nother = 1\n"))
+	config, _ := builder.WithMergePolicy(LastWins).LoadDotEnv(strings.NewReader("# comment\nCTX_OTHER=2\n"))
+
+	source, _ := config.Origin("key")
+	if (Source{Kind: SourceTxt, Line: 1}) != source {
+		t.Error("Wrong source found :", source)
+	}
+	source, _ = config.Origin("other")
+	if (Source{Kind: SourceDotEnv, Line: 2}) != source {
+		t.Error("Wrong source found :", source)
+	}
+
+	// Values set directly
+	impl := config.(*ConfigImpl)
+	impl.SetValue("direct", "value")
+	source, _ = config.Origin("direct")
+	if SourceUnknown != source.Kind {
+		t.Error("Wrong source found :", source)
+	}
+
+	expected := map[string]Source{
+		"txt file 'a.txt' line 3": {Kind: SourceTxt, File: "a.txt", Line: 3},
+		"json file 'a.json'":      {Kind: SourceJSON, File: "a.json"},
+		"env 'CTX_KEY'":           {Kind: SourceEnv, Env: "CTX_KEY"},
+		"txt line 2":              {Kind: SourceTxt, Line: 2},
+		"default":                 {Kind: SourceDefault},
+	}
+	for str, source := range expected {
+		if str != source.String() {
+			t.Error("Wrong String() value", source.String())
+		}
+	}
+}
+
+// Check origin of profile overlays, and of replaced or removed values.
+func TestSource2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetActiveProfiles("prod")
+	builder.SetMergePolicy(LastWins)
+	builder.LoadTxt(strings.NewReader("url = localhost\nprofiles.prod.url = prod.example.com\ndb = db.local\nname = app\nlog.level = info\n"))
+	config, _ := builder.LoadTxt(strings.NewReader("db.host = db.example.com\nname = !unset\nlog = stdout\n"))
+
+	source, _ := config.Origin("url")
+	if (Source{Kind: SourceTxt, Line: 2}) != source {
+		t.Error("Wrong source found for url :", source)
+	}
+	impl := config.(*ConfigImpl)
+	for _, key := range []string{"profiles.prod.url", "db", "name", "log.level"} {
+		if _, found := impl.def.origins[key]; found {
+			t.Error("Source of", key, "should be removed")
+		}
+	}
+	if source, found := config.Origin("name"); found {
+		t.Error("Key 'name' should be missing", source)
+	}
+	source, _ = config.Origin("db.host")
+	if (Source{Kind: SourceTxt, Line: 1}) != source {
+		t.Error("Wrong source found for db.host :", source)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
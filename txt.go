@@ -0,0 +1,279 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// tripleQuote delimiter of multi lines values.
+const tripleQuote = `"""`
+
+// includeDirective include another txt file, '@include?' ignore missing files.
+const includeDirective = "@include"
+
+// txtParser parse txt format line by line.
+type txtParser struct {
+	lines    []string
+	pos      int      // index of next line, i.e. number of current line (1 based)
+	fsys     fs.FS    // file system of included files, os when nil
+	filename string   // file being parsed, may be empty
+	stack    []string // absolute names of files being parsed, to detect include cycles
+}
+
+// next return next line, false when no more lines.
+func (p *txtParser) next() (string, bool) {
+	if p.pos >= len(p.lines) {
+		return "", false
+	}
+	line := p.lines[p.pos]
+	p.pos++
+	return line, true
+}
+
+// parseTxt read a text stream and store values into conf.
+// filename (may be empty) is used to resolve relative includes within fsys,
+// stack hold files already being parsed.
+func (b *ConfigBuilder) parseTxt(r io.Reader, fsys fs.FS, filename string, stack []string, loaded *sourceValues) error {
+	p := &txtParser{fsys: fsys, filename: filename, stack: stack}
+	if "" != filename {
+		abs, err := p.abs(filename)
+		if nil != err {
+			return err
+		}
+		p.stack = append(stack[:len(stack):len(stack)], abs)
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.lines = append(p.lines, scanner.Text())
+	}
+	if err := scanner.Err(); nil != err {
+		return err
+	}
+
+	for raw, ok := p.next(); ok; raw, ok = p.next() {
+		line := strings.TrimSpace(raw)
+		// ignore empty lines and comments
+		if "" == line || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if strings.HasPrefix(line, includeDirective) {
+			if err := b.parseInclude(p, line, loaded); nil != err {
+				return err
+			}
+			continue
+		}
+		start := p.pos
+		key, value, err := b.parseTxtLine(p, line)
+		if nil != err {
+			if perr, ok := err.(*ParseError); ok {
+				perr.file = filename
+			}
+			return err
+		}
+		// Set Value in a spare config
+		if unsetValue == value {
+			loaded.set(key, unset, filename, start, b.mergePolicy)
+		} else {
+			loaded.set(key, value, filename, start, b.mergePolicy)
+		}
+	}
+	return nil
+}
+
+// parseInclude handle '@include path' and '@include? path' lines.
+// path may be a glob pattern, matching files are included in lexical order.
+func (b *ConfigBuilder) parseInclude(p *txtParser, line string, loaded *sourceValues) error {
+	target := line[len(includeDirective):]
+	optional := strings.HasPrefix(target, "?")
+	if optional {
+		target = target[1:]
+	}
+	if "" == strings.TrimSpace(target) || !unicode.IsSpace(rune(target[0])) {
+		return &ParseError{file: p.filename, line: p.pos, msg: "invalid include : '" + line + "'"}
+	}
+	target = strings.TrimSpace(target)
+
+	filenames, err := p.resolve(target)
+	if nil != err {
+		return &ParseError{file: p.filename, line: p.pos, msg: err.Error() + " : '" + line + "'"}
+	}
+	for _, filename := range filenames {
+		abs, err := p.abs(filename)
+		if nil != err {
+			return err
+		}
+		for _, parsing := range p.stack {
+			if parsing == abs {
+				return &ParseError{file: p.filename, line: p.pos, msg: "include cycle : '" + filename + "'"}
+			}
+		}
+		f, err := openFile(p.fsys, filename)
+		if nil != err {
+			if (optional || b.ignoreMissingFiles) && os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		err = b.parseTxt(bufio.NewReader(f), p.fsys, filename, p.stack, loaded)
+		f.Close()
+		if nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve return files to include, relative to the file being parsed.
+func (p *txtParser) resolve(target string) ([]string, error) {
+	if nil == p.fsys {
+		if !filepath.IsAbs(target) && "" != p.filename {
+			target = filepath.Join(filepath.Dir(p.filename), target)
+		}
+		if strings.ContainsAny(target, "*?[") {
+			return filepath.Glob(target)
+		}
+		return []string{target}, nil
+	}
+	// fs.FS use slash separated, unrooted paths
+	if "" != p.filename {
+		target = path.Join(path.Dir(p.filename), target)
+	}
+	if strings.ContainsAny(target, "*?[") {
+		return fs.Glob(p.fsys, target)
+	}
+	return []string{path.Clean(target)}, nil
+}
+
+// abs return a unique name of a file, used to detect include cycles.
+func (p *txtParser) abs(filename string) (string, error) {
+	if nil == p.fsys {
+		return filepath.Abs(filename)
+	}
+	return path.Clean(filename), nil
+}
+
+// parseTxtLine parse a 'key = value' line, reading following lines
+// for multi lines values (key <<EOF or key = """).
+func (b *ConfigBuilder) parseTxtLine(p *txtParser, line string) (string, string, error) {
+	start := p.pos
+	eq := strings.Index(line, "=")
+	// Heredoc : 'key <<EOF' or 'key = <<EOF'
+	if hd := strings.Index(line, "<<"); hd >= 0 && (eq < 0 || hd < eq) {
+		return b.parseHeredoc(p, line[:hd], line[hd+2:])
+	}
+	if eq < 0 {
+		return "", "", &ParseError{line: start, msg: "missing '=' : '" + line + "'"}
+	}
+	key := strings.TrimSpace(line[:eq])
+	value := strings.TrimSpace(line[eq+1:])
+	if strings.HasPrefix(value, "<<") && isHeredocMarker(value[2:]) {
+		return b.parseHeredoc(p, key, value[2:])
+	}
+	if strings.HasPrefix(value, tripleQuote) {
+		return b.parseTripleQuote(p, key, value[len(tripleQuote):])
+	}
+	if b.inlineComments {
+		var err error
+		if value, err = stripInlineComment(value); nil != err {
+			return "", "", &ParseError{line: start, msg: err.Error() + " : '" + line + "'"}
+		}
+	}
+	return key, value, nil
+}
+
+// parseHeredoc read lines until the marker, lines are kept as is.
+func (b *ConfigBuilder) parseHeredoc(p *txtParser, key, marker string) (string, string, error) {
+	start := p.pos
+	key = strings.TrimSpace(key)
+	marker = strings.TrimSpace(marker)
+	if !isHeredocMarker(marker) {
+		return "", "", &ParseError{line: start, msg: "invalid heredoc marker '" + marker + "'"}
+	}
+	var value []string
+	for raw, ok := p.next(); ok; raw, ok = p.next() {
+		if marker == strings.TrimSpace(raw) {
+			return key, strings.Join(value, "\n"), nil
+		}
+		value = append(value, raw)
+	}
+	return "", "", &ParseError{line: start, msg: "missing heredoc end '" + marker + "'"}
+}
+
+// parseTripleQuote read lines until closing """.
+// a new line just after opening """ is ignored.
+func (b *ConfigBuilder) parseTripleQuote(p *txtParser, key, first string) (string, string, error) {
+	start := p.pos
+	value := first
+	for {
+		if end := strings.Index(value, tripleQuote); end >= 0 {
+			if !b.isTrailingComment(value[end+len(tripleQuote):]) {
+				return "", "", &ParseError{line: p.pos, msg: "unexpected chars after '" + tripleQuote + "'"}
+			}
+			return key, strings.TrimPrefix(value[:end], "\n"), nil
+		}
+		raw, ok := p.next()
+		if !ok {
+			return "", "", &ParseError{line: start, msg: "missing closing '" + tripleQuote + "'"}
+		}
+		value += "\n" + raw
+	}
+}
+
+// isTrailingComment check that remaining of a line is empty or a comment.
+func (b *ConfigBuilder) isTrailingComment(remain string) bool {
+	remain = strings.TrimSpace(remain)
+	return "" == remain || (b.inlineComments && strings.HasPrefix(remain, "#"))
+}
+
+// isHeredocMarker check if marker is a valid identifier (i.e. EOF, END_CERT).
+func isHeredocMarker(marker string) bool {
+	marker = strings.TrimSpace(marker)
+	if "" == marker {
+		return false
+	}
+	for i, r := range marker {
+		if !(('A' <= r && r <= 'Z') || ('a' <= r && r <= 'z') || '_' == r || (i > 0 && '0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripInlineComment remove a trailing ' # comment' from value.
+// A value quoted with " or ' is kept as is (without quotes).
+func stripInlineComment(value string) (string, error) {
+	if strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'") {
+		end := strings.IndexByte(value[1:], value[0])
+		if end < 0 {
+			return "", errors.New("missing closing quote")
+		}
+		remain := strings.TrimSpace(value[end+2:])
+		if "" != remain && !strings.HasPrefix(remain, "#") {
+			return "", errors.New("unexpected chars after quote")
+		}
+		return value[1 : end+1], nil
+	}
+	if strings.HasPrefix(value, "#") {
+		return "", nil
+	}
+	for i := 1; i < len(value); i++ {
+		if '#' == value[i] && (' ' == value[i-1] || '\t' == value[i-1]) {
+			return strings.TrimSpace(value[:i]), nil
+		}
+	}
+	return value, nil
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,179 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+// Check multi lines values.
+func TestTxt0(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	str := "key = value # not a comment\ncert <<EOF\n-----BEGIN CERT-----\n  abc=def\n-----END CERT-----\n  EOF\n" +
+		"sql = <<END_SQL\nSELECT *\nFROM t\nEND_SQL\nquoted = \"\"\"\nline1\nline2 = x\n\"\"\"\nsingle = \"\"\"one line\"\"\"\nlast = end\n"
+	config, err := builder.LoadTxt(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadTxt Failed", err)
+	}
+
+	expected := map[string]string{
+		"key":    "value # not a comment",
+		"cert":   "-----BEGIN CERT-----\n  abc=def\n-----END CERT-----",
+		"sql":    "SELECT *\nFROM t",
+		"quoted": "line1\nline2 = x\n",
+		"single": "one line",
+		"last":   "end",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+}
+
+// Check inline comments.
+func TestTxt1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	if builder.InlineComments() {
+		t.Error("Inline comments should be disabled by default")
+	}
+	builder.SetInlineComments(true)
+	str := "key = value # a comment\ncolor = \"#fff\" # white\nraw = 'a # b'\nurl=http://host/#anchor\nempty = # nothing\n" +
+		"quoted = \"\"\"x # y\"\"\" # comment\n"
+	config, err := builder.LoadTxt(strings.NewReader(str))
+
+	if nil != err {
+		t.Error("LoadTxt Failed", err)
+	}
+
+	expected := map[string]string{
+		"key":    "value",
+		"color":  "#fff",
+		"raw":    "a # b",
+		"url":    "http://host/#anchor",
+		"empty":  "",
+		"quoted": "x # y",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+}
+
+// Check line numbers of parse errors.
+func TestTxt2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.SetInlineComments(true)
+	cases := map[string]int{
+		"a = b\ncert <<EOF\nabc\n":                  2,
+		"a = b\nsql = \"\"\"\nabc\n\nnope\n":        2,
+		"a = <<EOF\nx\nEOF\nb = \"\"\"\n\"\"\" x\n": 5,
+		"a = <<EOF\nx\nEOF\nnope\n":                 4,
+		"a = \"open\n":                              1,
+		"a <<\nEOF\n":                               1,
+	}
+	for str, line := range cases {
+		_, err := builder.LoadTxt(strings.NewReader(str))
+		if nil == err {
+			t.Error("LoadTxt should Failed", str)
+		}
+		if perr, ok := err.(*ParseError); !ok || line != perr.line {
+			t.Error("Wrong error :", err, "line", line, "expected")
+		}
+	}
+}
+
+// Check include directives.
+func TestTxt3(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, err := builder.LoadTxtFile("testdata/include/main.conf")
+
+	if nil != err {
+		t.Error("LoadTxtFile Failed", err)
+	}
+
+	// included values are set before following lines
+	expected := map[string]string{
+		"name":    "main",
+		"port":    "10",
+		"db.host": "first",
+		"db.user": "john",
+	}
+	for key, value := range expected {
+		str, serr := config.GetString(key)
+		if nil != serr {
+			t.Error("Key '"+key+"' not found", serr)
+		}
+		if value != str {
+			t.Error("Wrong value found for", key, ":", str)
+		}
+	}
+
+	// Same through LoadFiles
+	builder = NewBuilder("Ctx_", nil)
+	config, err = builder.LoadFiles("testdata/include/main.conf")
+	if nil != err {
+		t.Error("LoadFiles Failed", err)
+	}
+	str, serr := config.GetString("db.user")
+	if nil != serr || "john" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// Check include of missing files and cycles.
+func TestTxt4(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	_, err := builder.LoadTxtFile("testdata/include/missing.conf.txt")
+	if nil == err {
+		t.Error("Include of missing file should Fail")
+	}
+
+	builder.SetIgnoreMissingFiles(true)
+	config, err := builder.LoadTxtFile("testdata/include/missing.conf.txt")
+	if nil != err {
+		t.Error("Include of missing file should be ignored", err)
+	}
+	str, serr := config.GetString("name")
+	if nil != serr || "missing" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+
+	_, err = builder.LoadTxtFile("testdata/include/cycle-a.conf")
+	if nil == err {
+		t.Error("Include cycle should Fail")
+	}
+	if perr, ok := err.(*ParseError); !ok || 3 != perr.line || !strings.HasSuffix(perr.file, "cycle-b.conf") {
+		t.Error("Wrong error :", err)
+	}
+
+	// Relative to working dir when read from a stream
+	config, err = builder.LoadTxt(strings.NewReader("@include testdata/config00.txt\n@includenope\n"))
+	if perr, ok := err.(*ParseError); !ok || 2 != perr.line {
+		t.Error("Wrong error :", err)
+	}
+	_, err = builder.LoadTxt(strings.NewReader("@include testdata/config00.txt\n"))
+	if nil != err {
+		t.Error("LoadTxt Failed", err)
+	}
+	str, serr = config.GetString("dev.db.pwd")
+	if nil != serr || "development" != str {
+		t.Error("Wrong value found :", str, serr)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
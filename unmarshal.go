@@ -0,0 +1,362 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagName name of struct tags read by Unmarshal, i.e. `config:"port"`.
+const tagName = "config"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// decoder decode config values into go values.
+type decoder struct {
+	conf    *ConfigImpl
+	err     UnmarshalError
+	missing []string // full keys of missing required values
+}
+
+// fieldTag name and options of a struct field tag,
+// i.e. `config:"port,default=5432"`, `config:"url,required"` or `config:"password,secret"`.
+// For Unmarshal, secret only hides the value from error messages,
+// AddDefaults is needed to redact it (see IsSecret).
+type fieldTag struct {
+	name     string
+	deflt    string
+	hasDeflt bool
+	required bool
+	secret   bool
+}
+
+// Unmarshal decode values of key (empty for whole config) into target,
+// a pointer to a struct, a map, a slice or a scalar.
+// Struct fields are read from the key given by their `config:"name"` tag,
+// or from their name in lower case, `config:"-"` fields are ignored.
+// Values are converted as getters do (i.e. "5s" for a Duration, "0x10" for an int),
+// and strings are expanded. Missing keys are ignored, unless the tag gives a default value
+// (`config:"port,default=5432"`) or mark them as required (`config:"url,required"`).
+// Values of fields tagged as secret (`config:"password,secret"`) are not part of error messages,
+// that is the only effect of the tag here : Unmarshal does not register secrets,
+// call AddDefaults so that IsSecret, Walk and map getters redact them.
+// All missing required keys are reported in a MissingKeyError,
+// otherwise every value that could not be decoded is reported in an UnmarshalError.
+func (c *ConfigImpl) Unmarshal(key string, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if reflect.Ptr != v.Kind() || v.IsNil() {
+		return errors.New("Unmarshal target must be a non nil pointer")
+	}
+	d := &decoder{conf: c}
+	if "" == normalizeKey(key) {
+		d.decode("", c.values, true, true, v.Elem())
+	} else {
+		raw, found, err := c.get(key)
+		if nil != err {
+			return err
+		}
+		d.decode(key, raw, found, true, v.Elem())
+	}
+	if len(d.missing) > 0 {
+		return &MissingKeyError{key: d.missing[0], keys: d.missing}
+	}
+	if len(d.err.keys) > 0 {
+		return &d.err
+	}
+	return nil
+}
+
+// AddDefaults add default values and secret markers declared in tags of target fields,
+// target is a struct, or a pointer to a struct, decoded from key (see Unmarshal).
+// Only fields of nested structs are registered, not elements of slices or maps.
+func (b *ConfigBuilder) AddDefaults(key string, target interface{}) {
+	t := reflect.TypeOf(target)
+	for nil != t && reflect.Ptr == t.Kind() {
+		t = t.Elem()
+	}
+	if nil != t && reflect.Struct == t.Kind() && timeType != t {
+		registerTags(b.conf.def, normalizeKey(key), t)
+	}
+}
+
+// registerTags add defaults and secrets declared in tags of the fields of t.
+func registerTags(def *ConfigDefault, key string, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := parseTag(field)
+		if ignoredField(field) || "-" == tag.name {
+			continue
+		}
+		fieldKey := joinKey(key, tag.name)
+		if field.Anonymous && "" == field.Tag.Get(tagName) {
+			fieldKey = key
+		}
+		if tag.secret {
+			def.markSecret(fieldKey)
+		}
+		if tag.hasDeflt {
+			def.AddDefault(fieldKey, tag.deflt)
+		}
+		ft := field.Type
+		for reflect.Ptr == ft.Kind() {
+			ft = ft.Elem()
+		}
+		if reflect.Struct == ft.Kind() && timeType != ft {
+			registerTags(def, fieldKey, ft)
+		}
+	}
+}
+
+// fail record an error for key.
+func (d *decoder) fail(key string, err error) {
+	d.err.keys = append(d.err.keys, d.conf.fullKey(key))
+	d.err.errs = append(d.err.errs, err)
+}
+
+// decode store raw, value of key, into v.
+// When addressable is true, values of struct fields are searched with their keys
+// (as getters do, in defaults and env vars), otherwise within raw.
+// Strings are expanded before being converted.
+func (d *decoder) decode(key string, raw interface{}, found, addressable bool, v reflect.Value) {
+	if !found || nil == raw {
+		// fields of a missing section may be found in defaults or env vars
+		if addressable && reflect.Struct == v.Kind() && timeType != v.Type() {
+			d.decodeStruct(key, nil, addressable, v)
+		}
+		return
+	}
+	// items of lists are expanded one by one
+	if str, ok := raw.(string); ok && reflect.Ptr != v.Kind() && reflect.Interface != v.Kind() && reflect.Slice != v.Kind() {
+		expanded, err := d.conf.Expand(str)
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		raw = expanded
+	}
+	switch {
+	case timeType == v.Type():
+		t, err := toTime(raw, d.conf.fullKey(key))
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		v.Set(reflect.ValueOf(t))
+		return
+	case durationType == v.Type():
+		duration, err := toDuration(raw)
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		v.SetInt(int64(duration))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.decode(key, raw, found, addressable, v.Elem())
+	case reflect.Struct:
+		d.decodeStruct(key, raw, addressable, v)
+	case reflect.Map:
+		d.decodeMap(key, raw, v)
+	case reflect.Slice:
+		d.decodeSlice(key, raw, v)
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			d.fail(key, fmt.Errorf("unsupported type %s", v.Type()))
+			return
+		}
+		v.Set(reflect.ValueOf(d.conf.Translate(raw)))
+	case reflect.String:
+		v.SetString(fmt.Sprint(raw))
+	case reflect.Bool:
+		b, err := toBool(raw)
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt(raw)
+		if nil == err && v.OverflowInt(i) {
+			err = fmt.Errorf("value %d overflows %s", i, v.Type())
+		}
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := toUint(raw)
+		if nil == err && v.OverflowUint(u) {
+			err = fmt.Errorf("value %d overflows %s", u, v.Type())
+		}
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat(raw)
+		if nil != err {
+			d.fail(key, err)
+			return
+		}
+		v.SetFloat(f)
+	default:
+		d.fail(key, fmt.Errorf("unsupported type %s", v.Type()))
+	}
+}
+
+// decodeStruct decode each exported field of a struct.
+// Embedded structs without tag are decoded from the same key.
+func (d *decoder) decodeStruct(key string, raw interface{}, addressable bool, v reflect.Value) {
+	values, isMap := raw.(map[string]interface{})
+	if !isMap && (nil != raw || !addressable) {
+		d.fail(key, fmt.Errorf("can not decode %T into %s", raw, v.Type()))
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if ignoredField(field) {
+			continue
+		}
+		tag := parseTag(field)
+		if "-" == tag.name {
+			continue
+		}
+		if field.Anonymous && "" == field.Tag.Get(tagName) {
+			d.decode(key, raw, true, addressable, v.Field(i))
+			continue
+		}
+		fieldKey := joinKey(key, tag.name)
+		var fieldRaw interface{}
+		var found bool
+		var err error
+		if addressable {
+			fieldRaw, found, err = d.conf.get(fieldKey)
+		} else if fieldRaw, found = values[tag.name]; found && isUnset(fieldRaw) {
+			fieldRaw, found = nil, false
+		}
+		if nil != err {
+			d.fail(fieldKey, err)
+			continue
+		}
+		if !found && tag.hasDeflt {
+			fieldRaw, found = tag.deflt, true
+		}
+		if !found && tag.required {
+			d.missing = append(d.missing, d.conf.fullKey(fieldKey))
+			continue
+		}
+		if !tag.secret {
+			d.decode(fieldKey, fieldRaw, found, addressable, v.Field(i))
+			continue
+		}
+		// do not report secret values within errors
+		failed := len(d.err.errs)
+		d.decode(fieldKey, fieldRaw, found, addressable, v.Field(i))
+		for j := failed; j < len(d.err.errs); j++ {
+			d.err.errs[j] = errors.New("invalid secret value")
+		}
+	}
+}
+
+// decodeMap decode a map with string keys.
+func (d *decoder) decodeMap(key string, raw interface{}, v reflect.Value) {
+	values, ok := raw.(map[string]interface{})
+	if !ok || reflect.String != v.Type().Key().Kind() {
+		d.fail(key, fmt.Errorf("can not decode %T into %s", raw, v.Type()))
+		return
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(values)))
+	}
+	for name, value := range values {
+		if isUnset(value) {
+			continue
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		d.decode(joinKey(key, name), value, true, false, elem)
+		v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+	}
+}
+
+// decodeSlice decode an array or a separated string (see GetStringSlice),
+// elements keys are key.0, key.1 ...
+func (d *decoder) decodeSlice(key string, raw interface{}, v reflect.Value) {
+	if _, ok := raw.(map[string]interface{}); ok {
+		d.fail(key, fmt.Errorf("can not decode %T into %s", raw, v.Type()))
+		return
+	}
+	values, err := d.conf.toList(raw)
+	if nil != err {
+		d.fail(key, err)
+		return
+	}
+	result := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i, value := range values {
+		d.decode(joinKey(key, strconv.Itoa(i)), value, true, false, result.Index(i))
+	}
+	v.Set(result)
+}
+
+// ignoredField check if a field can not be decoded : unexported fields,
+// except embedded structs whose exported fields are decoded (as encoding/json does).
+func ignoredField(field reflect.StructField) bool {
+	if "" == field.PkgPath {
+		return false
+	}
+	return !field.Anonymous || reflect.Struct != field.Type.Kind()
+}
+
+// parseTag read the tag of a struct field, name is the field name in lower case when missing.
+// default option must be the last one when its value hold a comma.
+func parseTag(field reflect.StructField) fieldTag {
+	parts := strings.Split(field.Tag.Get(tagName), ",")
+	result := fieldTag{name: strings.TrimSpace(parts[0])}
+	if "" == result.name {
+		result.name = strings.ToLower(field.Name)
+	}
+	for i := 1; i < len(parts); i++ {
+		option := strings.TrimSpace(parts[i])
+		switch {
+		case "required" == option:
+			result.required = true
+		case "secret" == option:
+			result.secret = true
+		case strings.HasPrefix(option, "default="):
+			result.deflt = strings.Join(parts[i:], ",")
+			result.deflt = result.deflt[strings.Index(result.deflt, "=")+1:]
+			result.hasDeflt = true
+			return result
+		}
+	}
+	return result
+}
+
+// joinKey append name to a dotted key.
+func joinKey(key, name string) string {
+	if "" == key {
+		return name
+	}
+	return key + "." + name
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai
@@ -0,0 +1,223 @@
+/*
+Copyright (c) Jean-François PHILIPPE 2017-2018
+Package goconfig read config files.
+*/
+
+package goconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	Host string `config:"host"`
+	Port uint16 `config:"port"`
+}
+
+type testBase struct {
+	Name string `config:"name"`
+}
+
+type testConfig struct {
+	testBase
+	Debug    bool              `config:"debug"`
+	Timeout  time.Duration     `config:"timeout"`
+	MaxConns int               `config:"max_conns"`
+	Ratio    float32           `config:"ratio"`
+	URL      string            `config:"url"`
+	Servers  []testServer      `config:"servers"`
+	Primary  *testServer       `config:"primary"`
+	Backup   *testServer       `config:"backup"`
+	Labels   map[string]string `config:"labels"`
+	Extra    interface{}       `config:"extra"`
+	Start    time.Time         `config:"start"`
+	Level    string
+	Ignored  string `config:"-"`
+	private  string
+}
+
+// Check Unmarshal of a whole config.
+func TestUnmarshal0(t *testing.T) {
+	os.Setenv("CTX_LEVEL", "debug")
+	defer os.Unsetenv("CTX_LEVEL")
+	builder := NewBuilder("Ctx_", map[string]interface{}{"ratio": 0.5})
+	config, err := builder.LoadJSON(strings.NewReader(`{
+		"name": "app",
+		"debug": "true",
+		"timeout": "5s",
+		"max_conns": "0x10",
+		"host": "example.com",
+		"url": "http://${host}:${primary.port}/",
+		"servers": [ { "host": "a", "port": 1 }, { "host": "${host}", "port": "2" } ],
+		"primary": { "host": "p", "port": 80 },
+		"labels": { "env": "prod", "host": "${host}" },
+		"extra": { "key": "${name}" },
+		"start": "2018-01-02",
+		"ignored": "ignored"
+	}`))
+	if nil != err {
+		t.Error("LoadJSON Failed", err)
+	}
+
+	var result testConfig
+	if err = config.Unmarshal("", &result); nil != err {
+		t.Error("Unmarshal Failed", err)
+	}
+	if "app" != result.Name || !result.Debug || 5*time.Second != result.Timeout || 16 != result.MaxConns || 0.5 != result.Ratio {
+		t.Error("Wrong scalar values", result)
+	}
+	if "http://example.com:80/" != result.URL || "debug" != result.Level || "" != result.Ignored {
+		t.Error("Wrong string values", result)
+	}
+	if 2 != len(result.Servers) || "a" != result.Servers[0].Host || "example.com" != result.Servers[1].Host || 2 != result.Servers[1].Port {
+		t.Error("Wrong servers", result.Servers)
+	}
+	if nil == result.Primary || "p" != result.Primary.Host || nil != result.Backup {
+		t.Error("Wrong pointers", result.Primary, result.Backup)
+	}
+	if "prod" != result.Labels["env"] || "example.com" != result.Labels["host"] {
+		t.Error("Wrong labels", result.Labels)
+	}
+	if extra, ok := result.Extra.(map[string]interface{}); !ok || "app" != extra["key"] {
+		t.Error("Wrong extra", result.Extra)
+	}
+	if 2018 != result.Start.Year() {
+		t.Error("Wrong start", result.Start)
+	}
+
+	// a section
+	var server testServer
+	if err = config.Unmarshal("primary", &server); nil != err || 80 != server.Port {
+		t.Error("Unmarshal of section Failed", server, err)
+	}
+	var ports map[string]int
+	if err = config.Unmarshal("primary", &ports); nil == err {
+		t.Error("Unmarshal of 'host' into int should fail", ports)
+	}
+	if nil == config.Unmarshal("", result) {
+		t.Error("Unmarshal into a non pointer should fail")
+	}
+}
+
+// Check every failing field is reported with its full key.
+func TestUnmarshal1(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	config, _ := builder.LoadJSON(strings.NewReader(`{
+		"app": {
+			"debug": "maybe",
+			"timeout": "5 seconds",
+			"servers": [ { "host": "a", "port": 1 }, { "host": "b", "port": 100000 } ],
+			"url": "${missing}"
+		}
+	}`))
+	sub, err := config.GetConfig("app")
+	if nil != err {
+		t.Error("GetConfig Failed", err)
+	}
+	var result testConfig
+	err = sub.Unmarshal("", &result)
+	uerr, ok := err.(*UnmarshalError)
+	if !ok {
+		t.Error("UnmarshalError expected", err)
+		return
+	}
+	expected := []string{"app.debug", "app.timeout", "app.url", "app.servers.1.port"}
+	keys := uerr.Keys()
+	if len(expected) != len(keys) {
+		t.Error("Wrong keys", keys)
+		return
+	}
+	for i, key := range expected {
+		if key != keys[i] {
+			t.Error("Wrong key", i, key, keys[i])
+		}
+	}
+	if "a" != result.Servers[0].Host {
+		t.Error("Valid values should be decoded", result.Servers)
+	}
+}
+
+type testDatabase struct {
+	URL      string        `config:"url,required"`
+	User     string        `config:"user,required"`
+	Port     int           `config:"port,default=5432"`
+	Timeout  time.Duration `config:"timeout,default=5s"`
+	Hosts    []testServer  `config:"hosts"`
+	Password int           `config:"password,secret"`
+	Options  string        `config:"options,default=a=1,b=2"`
+}
+
+// Check default, required and secret tag options.
+func TestUnmarshal2(t *testing.T) {
+	builder := NewBuilder("Ctx_", nil)
+	builder.AddDefaults("database", &testDatabase{})
+	config, _ := builder.LoadJSON(strings.NewReader(`{
+		"database": { "url": "db://", "user": "john", "hosts": [ { "host": "a" } ] },
+		"broken": { "password": "p@ss" }
+	}`))
+
+	// defaults are registered
+	port, err := config.GetInt("database.port")
+	if nil != err || 5432 != port {
+		t.Error("Default not registered", port, err)
+	}
+	if !config.IsSecret("database.password") || config.IsSecret("database.url") {
+		t.Error("Wrong secret keys")
+	}
+
+	var db testDatabase
+	if err = config.Unmarshal("database", &db); nil != err {
+		t.Error("Unmarshal Failed", err)
+	}
+	if "db://" != db.URL || 5432 != db.Port || 5*time.Second != db.Timeout || "a=1,b=2" != db.Options {
+		t.Error("Wrong values", db)
+	}
+
+	// required keys are all reported
+	err = config.Unmarshal("broken", &db)
+	merr, ok := err.(*MissingKeyError)
+	if !ok {
+		t.Error("MissingKeyError expected", err)
+		return
+	}
+	if keys := merr.Keys(); 2 != len(keys) || "broken.url" != keys[0] || "broken.user" != keys[1] {
+		t.Error("Wrong missing keys", keys)
+	}
+
+	// secret values are not part of errors
+	config, _ = NewBuilder("Ctx_", nil).LoadJSON(strings.NewReader(`{ "url": "db://", "user": "john", "password": "p@ss" }`))
+	err = config.Unmarshal("", &db)
+	if nil == err || strings.Contains(err.Error(), "p@ss") {
+		t.Error("Secret value should not be reported", err)
+	}
+	if config.IsSecret("password") {
+		t.Error("Unmarshal should not mark secrets")
+	}
+}
+
+type testInner struct {
+	Host string `config:"host"`
+}
+
+type testOuter struct {
+	*testInner
+	testBase
+	Port int `config:"port"`
+}
+
+// Check embedded unexported structs : pointers are ignored, values are decoded.
+func TestUnmarshal3(t *testing.T) {
+	config, _ := NewBuilder("Ctx_", nil).LoadJSON(strings.NewReader(`{ "host": "h", "name": "n", "port": 80 }`))
+	var result testOuter
+	if err := config.Unmarshal("", &result); nil != err {
+		t.Error("Unmarshal Failed", err)
+	}
+	if nil != result.testInner || "n" != result.Name || 80 != result.Port {
+		t.Error("Wrong values", result)
+	}
+}
+
+// vi:set fileencoding=utf-8 tabstop=4 ai